@@ -0,0 +1,80 @@
+package eip7702
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// AccessListReport compara o gas da SetCodeTx com e sem a access list sugerida pelo node
+type AccessListReport struct {
+	Attached   bool             `json:"attached"`
+	GasWithout uint64           `json:"gas_without"`
+	GasWith    uint64           `json:"gas_with,omitempty"`
+	Saving     uint64           `json:"saving"`
+	List       types.AccessList `json:"list,omitempty"`
+	Reason     string           `json:"reason,omitempty"` // por que não foi anexada
+}
+
+// applyAccessList gera a access list via eth_createAccessList e a anexa à estimativa
+// só se a tx ficar mais barata. Qualquer falha apenas mantém a tx sem access list.
+func (d *DelegationService) applyAccessList(ctx context.Context, gas *GasEstimate, from, to common.Address, auths []*Authorization, txData []byte) {
+	report := &AccessListReport{GasWithout: gas.Estimated}
+	gas.AccessList = report
+
+	if gas.Source != GasFromEstimate {
+		report.Reason = "gas estimate unavailable"
+		return
+	}
+
+	authList := make([]types.SetCodeAuthorization, len(auths))
+	for i, auth := range auths {
+		authList[i] = auth.toSetCodeAuthorization()
+	}
+	msg := ethereum.CallMsg{
+		From:              from,
+		To:                &to,
+		Data:              txData,
+		AuthorizationList: authList,
+	}
+
+	list, _, err := d.RPC.CreateAccessList(ctx, msg)
+	if err != nil {
+		report.Reason = fmt.Sprintf("eth_createAccessList failed: %v", err)
+		return
+	}
+	if len(list) == 0 {
+		report.Reason = "no accounts or slots to pre-warm"
+		return
+	}
+
+	// Comparar com eth_estimateGas: inclui o custo intrínseco da própria lista
+	msg.AccessList = list
+	withList, err := d.RPC.EstimateGas(ctx, msg)
+	if err != nil {
+		report.Reason = fmt.Sprintf("gas estimation with access list failed: %v", err)
+		return
+	}
+	report.GasWith = withList
+	if withList >= gas.Estimated {
+		report.Reason = "access list does not reduce gas"
+		return
+	}
+
+	report.Attached = true
+	report.Saving = gas.Estimated - withList
+	report.List = list
+	gas.Estimated = withList
+	gas.Floor += accessListGas(list)
+	gas.Limit = d.withGasMargin(max(withList, gas.Floor))
+}
+
+// accessListGas é o custo intrínseco da access list (EIP-2930)
+func accessListGas(list types.AccessList) uint64 {
+	return uint64(len(list))*params.TxAccessListAddressGas +
+		uint64(list.StorageKeys())*params.TxAccessListStorageKeyGas
+}
@@ -0,0 +1,78 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// accessListRPC sugere uma access list e estima o gas com ela
+type accessListRPC struct {
+	EthClient
+	list    types.AccessList
+	listErr error
+	gasWith uint64
+}
+
+func (r *accessListRPC) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	return r.list, 0, r.listErr
+}
+
+func (r *accessListRPC) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	if msg.AccessList == nil {
+		return 0, errors.New("estimate without access list")
+	}
+	return r.gasWith, nil
+}
+
+func TestApplyAccessList(t *testing.T) {
+	list := types.AccessList{{Address: common.Address{0xaa}, StorageKeys: []common.Hash{{1}, {2}}}}
+	auths := []*Authorization{{ChainID: 1337, Address: common.Address{1}, Signer: common.Address{4}}}
+
+	tests := []struct {
+		name     string
+		source   GasSource
+		rpc      *accessListRPC
+		attached bool
+		reason   string
+	}{
+		{"cheaper", GasFromEstimate, &accessListRPC{list: list, gasWith: 90_000}, true, ""},
+		{"same gas", GasFromEstimate, &accessListRPC{list: list, gasWith: 100_000}, false, "access list does not reduce gas"},
+		{"more gas", GasFromEstimate, &accessListRPC{list: list, gasWith: 105_000}, false, "access list does not reduce gas"},
+		{"empty list", GasFromEstimate, &accessListRPC{gasWith: 90_000}, false, "no accounts or slots to pre-warm"},
+		{"create fails", GasFromEstimate, &accessListRPC{listErr: errors.New("method not found")}, false, "eth_createAccessList failed: method not found"},
+		{"no estimate", GasFromFallback, &accessListRPC{list: list, gasWith: 90_000}, false, "gas estimate unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DelegationService{RPC: tt.rpc, GasMarginPercent: 20}
+			gas := &GasEstimate{Estimated: 100_000, Floor: 60_000, Limit: 120_000, Source: tt.source}
+			d.applyAccessList(context.Background(), gas, common.Address{2}, common.Address{4}, auths, nil)
+
+			report := gas.AccessList
+			if report == nil || report.Attached != tt.attached || report.Reason != tt.reason {
+				t.Fatalf("report = %+v", report)
+			}
+			if report.GasWithout != 100_000 {
+				t.Fatalf("gas without = %d, want 100000", report.GasWithout)
+			}
+			if !tt.attached {
+				if gas.Estimated != 100_000 || gas.Floor != 60_000 || gas.Limit != 120_000 || report.List != nil {
+					t.Fatalf("gas changed without attaching the list: %+v", gas)
+				}
+				return
+			}
+			// O piso inclui o custo intrínseco da lista: 2400 por conta + 1900 por slot
+			if gas.Estimated != 90_000 || gas.Floor != 60_000+2400+2*1900 || gas.Limit != 108_000 {
+				t.Fatalf("gas = %+v", gas)
+			}
+			if report.Saving != 10_000 || len(report.List) != 1 {
+				t.Fatalf("report = %+v", report)
+			}
+		})
+	}
+}
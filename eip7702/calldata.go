@@ -55,6 +55,16 @@ func init() {
 				{"name": "amount", "type": "uint256"}
 			]
 		},
+		{
+			"name": "transferFrom",
+			"type": "function",
+			"inputs": [
+				{"name": "token", "type": "address"},
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "amount", "type": "uint256"}
+			]
+		},
 		{
 			"name": "sendETH",
 			"type": "function",
@@ -62,6 +72,26 @@ func init() {
 				{"name": "to", "type": "address"},
 				{"name": "amount", "type": "uint256"}
 			]
+		},
+		{
+			"name": "Executed",
+			"type": "event",
+			"inputs": [
+				{"name": "to", "type": "address", "indexed": true},
+				{"name": "value", "type": "uint256"},
+				{"name": "data", "type": "bytes"}
+			]
+		},
+		{
+			"name": "TokenOperation",
+			"type": "event",
+			"inputs": [
+				{"name": "operation", "type": "string"},
+				{"name": "token", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "amount", "type": "uint256"},
+				{"name": "success", "type": "bool"}
+			]
 		}
 	]`
 
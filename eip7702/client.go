@@ -3,42 +3,191 @@ package eip7702
 import (
 	"context"
 	"math/big"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// DefaultRPCTimeout é o limite de uma chamada ao node sem timeout específico
+const DefaultRPCTimeout = 10 * time.Second
+
+// RPCTimeouts limita cada chamada ao node, além do contexto de quem chama
+// (o request HTTP). Methods usa o nome JSON-RPC, ex.: "eth_estimateGas".
+type RPCTimeouts struct {
+	Default time.Duration
+	Methods map[string]time.Duration
+}
+
+// DefaultRPCTimeouts dá mais folga para as chamadas que executam a tx no node
+func DefaultRPCTimeouts() RPCTimeouts {
+	return RPCTimeouts{
+		Default: DefaultRPCTimeout,
+		Methods: map[string]time.Duration{
+			"eth_estimateGas":        20 * time.Second,
+			"eth_createAccessList":   20 * time.Second,
+			"eth_call":               20 * time.Second,
+			"eth_sendRawTransaction": 30 * time.Second,
+		},
+	}
+}
+
+// For devolve o timeout do método (0 = sem limite próprio)
+func (t RPCTimeouts) For(method string) time.Duration {
+	if d, ok := t.Methods[method]; ok {
+		return d
+	}
+	return t.Default
+}
+
 // EthRPCClient implementa EthClient usando ethclient
 type EthRPCClient struct {
-	client *ethclient.Client
-	ctx    context.Context
+	client   *ethclient.Client
+	geth     *gethclient.Client // eth_call com state override
+	timeouts RPCTimeouts
 }
 
-func NewEthRPCClient(rpcURL string) (*EthRPCClient, error) {
-	client, err := ethclient.Dial(rpcURL)
+func NewEthRPCClient(rpcURL string, timeouts RPCTimeouts) (*EthRPCClient, error) {
+	client, err := rpc.Dial(rpcURL)
 	if err != nil {
 		return nil, err
 	}
+	return NewEthRPCClientFromRPC(client, timeouts), nil
+}
 
+// NewEthRPCClientFromRPC usa uma conexão já aberta (ex.: o node em processo do
+// backend simulado do go-ethereum)
+func NewEthRPCClientFromRPC(client *rpc.Client, timeouts RPCTimeouts) *EthRPCClient {
 	return &EthRPCClient{
-		client: client,
-		ctx:    context.Background(),
-	}, nil
+		client:   ethclient.NewClient(client),
+		geth:     gethclient.New(client),
+		timeouts: timeouts,
+	}
+}
+
+// withTimeout aplica o timeout do método sobre o contexto recebido
+func (e *EthRPCClient) withTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	if d := e.timeouts.For(method); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (e *EthRPCClient) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getTransactionCount")
+	defer cancel()
+	return e.client.NonceAt(ctx, from, nil)
+}
+
+func (e *EthRPCClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_maxPriorityFeePerGas")
+	defer cancel()
+	return e.client.SuggestGasTipCap(ctx)
+}
+
+func (e *EthRPCClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	ctx, cancel := e.withTimeout(ctx, "eth_sendRawTransaction")
+	defer cancel()
+	return e.client.SendTransaction(ctx, tx)
+}
+
+func (e *EthRPCClient) ChainID(ctx context.Context) (*big.Int, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_chainId")
+	defer cancel()
+	return e.client.ChainID(ctx)
 }
 
-func (e *EthRPCClient) NonceAt(from common.Address) (uint64, error) {
-	return e.client.NonceAt(e.ctx, from, nil)
+func (e *EthRPCClient) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getBalance")
+	defer cancel()
+	return e.client.BalanceAt(ctx, account, nil)
 }
 
-func (e *EthRPCClient) SuggestGasTipCap() (*big.Int, error) {
-	return e.client.SuggestGasTipCap(e.ctx)
+func (e *EthRPCClient) CodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getCode")
+	defer cancel()
+	return e.client.CodeAt(ctx, account, nil)
 }
 
-func (e *EthRPCClient) SendTransaction(tx *types.Transaction) error {
-	return e.client.SendTransaction(e.ctx, tx)
+func (e *EthRPCClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getTransactionReceipt")
+	defer cancel()
+	return e.client.TransactionReceipt(ctx, hash)
 }
 
-func (e *EthRPCClient) ChainID() (*big.Int, error) {
-	return e.client.ChainID(e.ctx)
+func (e *EthRPCClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_estimateGas")
+	defer cancel()
+	return e.client.EstimateGas(ctx, msg)
 }
+
+func (e *EthRPCClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getBlockByNumber")
+	defer cancel()
+	return e.client.HeaderByNumber(ctx, number)
+}
+
+func (e *EthRPCClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_feeHistory")
+	defer cancel()
+	return e.client.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+}
+
+func (e *EthRPCClient) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_call")
+	defer cancel()
+	if len(codeOverrides) == 0 {
+		return e.client.CallContract(ctx, msg, nil)
+	}
+	overrides := make(map[common.Address]gethclient.OverrideAccount, len(codeOverrides))
+	for addr, code := range codeOverrides {
+		overrides[addr] = gethclient.OverrideAccount{Code: code}
+	}
+	return e.geth.CallContract(ctx, msg, nil, &overrides)
+}
+
+func (e *EthRPCClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getTransactionCount")
+	defer cancel()
+	return e.client.PendingNonceAt(ctx, account)
+}
+
+func (e *EthRPCClient) BlockNumber(ctx context.Context) (uint64, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_blockNumber")
+	defer cancel()
+	return e.client.BlockNumber(ctx)
+}
+
+func (e *EthRPCClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_getTransactionByHash")
+	defer cancel()
+	return e.client.TransactionByHash(ctx, hash)
+}
+
+// CreateAccessList chama eth_createAccessList; erro de execução vira error
+func (e *EthRPCClient) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	ctx, cancel := e.withTimeout(ctx, "eth_createAccessList")
+	defer cancel()
+	list, gasUsed, execErr, err := e.geth.CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if execErr != "" {
+		return nil, gasUsed, executionError(execErr)
+	}
+	if list == nil {
+		return types.AccessList{}, gasUsed, nil
+	}
+	return *list, gasUsed, nil
+}
+
+// executionError é uma falha de execução reportada pelo node, não de transporte
+// (implementa rpc.Error como os erros JSON-RPC do ethclient)
+type executionError string
+
+func (e executionError) Error() string  { return string(e) }
+func (e executionError) ErrorCode() int { return -32000 }
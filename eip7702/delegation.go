@@ -1,13 +1,14 @@
 package eip7702
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -24,7 +25,7 @@ type Authorization struct {
 	R         [32]byte       `json:"r"`
 	S         [32]byte       `json:"s"`
 	Signer    common.Address `json:"signer"`     // EOA que delega
-	CreatedAt int64          `json:"created_at"` // Timestamp para validação
+	CreatedAt int64          `json:"created_at"` // Informativo: a validade é controlada pelo AuthorizationStore
 }
 
 // Call com validações de segurança
@@ -39,88 +40,244 @@ type Call struct {
 type AuthorizeRequest struct {
 	ContractAddress string `json:"contract_address"` // Endereço do contrato para delegar
 	SignerPK        string `json:"signer_pk"`        // Chave privada de quem autoriza
+	Universal       bool   `json:"universal"`        // chain_id = 0 (válida em todas as chains)
+}
+
+// RevokeRequest é o payload para revogar a delegação (delegar para 0x0)
+type RevokeRequest struct {
+	SignerPK      string         `json:"signer_pk,omitempty"`       // Chave privada da authority
+	Authorization *Authorization `json:"authorization,omitempty"`   // Revogação pré-assinada (substitui signer_pk)
+	Sponsor       string         `json:"sponsor,omitempty"`         // Nome do sponsor configurado (vazio = padrão)
+	MaxFeePerGas  string         `json:"max_fee_per_gas,omitempty"` // Teto do fee por gas em wei (opcional)
+	Broadcast     *bool          `json:"broadcast,omitempty"`       // false = devolve a tx assinada sem enviar
+	WaitSeconds   int            `json:"wait_seconds,omitempty"`    // Espera opcional pela mineração (máx. 15s)
+}
+
+// BatchSponsorRequest é o payload para patrocinar várias autorizações em uma tx
+type BatchSponsorRequest struct {
+	Authorizations []*Authorization `json:"authorizations"`            // Autorizações assinadas
+	Sponsor        string           `json:"sponsor,omitempty"`         // Nome do sponsor configurado (vazio = padrão)
+	MaxFeePerGas   string           `json:"max_fee_per_gas,omitempty"` // Teto do fee por gas em wei (opcional)
+	Broadcast      *bool            `json:"broadcast,omitempty"`       // false = devolve a tx assinada sem enviar
 }
 
 // SponsorRequest é o payload para execução patrocinada
 type SponsorRequest struct {
-	Authorization Authorization `json:"authorization"` // Autorização assinada
-	Calls         []CallData    `json:"calls"`         // Chamadas a executar
-	SponsorPK     string        `json:"sponsor_pk"`    // Chave privada do patrocinador
+	Authorization Authorization `json:"authorization"`             // Autorização assinada
+	Calls         []CallData    `json:"calls"`                     // Chamadas a executar
+	Sponsor       string        `json:"sponsor,omitempty"`         // Nome do sponsor configurado (vazio = padrão)
+	MaxFeePerGas  string        `json:"max_fee_per_gas,omitempty"` // Teto do fee por gas em wei (opcional)
+	Simulate      bool          `json:"simulate,omitempty"`        // eth_call antes de assinar; revert não é enviado
+	Broadcast     *bool         `json:"broadcast,omitempty"`       // false = devolve a tx assinada sem enviar
+	AccessList    bool          `json:"access_list,omitempty"`     // anexa access list se reduzir o gas
 }
 
 // CallData representa dados de chamada via JSON
 type CallData struct {
-	To    string `json:"to"`    // Endereço do contrato
-	Data  string `json:"data"`  // Dados hexadecimais
-	Value string `json:"value"` // Valor em wei (string para grandes números)
+	To       string `json:"to"`                  // Endereço do contrato
+	Data     string `json:"data"`                // Dados hexadecimais
+	Value    string `json:"value"`               // Valor em wei (string para grandes números)
+	GasLimit uint64 `json:"gas_limit,omitempty"` // Gas da call; piso da estimativa e usado se o node não estimar tipo 4
 }
 
-// SecureDelegationRequest com todas as validações EIP-7702
+// SelfExecuteRequest é o payload para execução em que a authority paga o próprio gas
+type SelfExecuteRequest struct {
+	ContractAddress string     `json:"contract_address"`          // Contrato para delegar
+	SignerPK        string     `json:"signer_pk"`                 // Chave privada da authority (também sender)
+	Calls           []CallData `json:"calls"`                     // Chamadas a executar
+	Universal       bool       `json:"universal"`                 // chain_id = 0 (requer política do servidor)
+	MaxFeePerGas    string     `json:"max_fee_per_gas,omitempty"` // Teto do fee por gas em wei (opcional)
+	Broadcast       *bool      `json:"broadcast,omitempty"`       // false = devolve a tx assinada sem enviar
+	AccessList      bool       `json:"access_list,omitempty"`     // anexa access list se reduzir o gas
+}
+
+// SecureDelegationRequest com todas as validações EIP-7702 (payload de /sponsor-secure)
 type SecureDelegationRequest struct {
 	ContractAddress common.Address `json:"contract_address"`
-	SignerPK        string         `json:"signer_pk"`
+	SignerPK        string         `json:"signer_pk,omitempty"`
+	Authorization   *Authorization `json:"authorization,omitempty"` // Autorização pré-assinada (substitui signer_pk)
+	Calls           []CallData     `json:"calls"`
+	Sponsor         string         `json:"sponsor,omitempty"` // Nome do sponsor configurado (vazio = padrão)
+	Simulate        bool           `json:"simulate,omitempty"`
+	Broadcast       *bool          `json:"broadcast,omitempty"`
+	AccessList      bool           `json:"access_list,omitempty"`
 
 	// VALIDAÇÕES DE SEGURANÇA
-	MaxGasPrice   *big.Int `json:"max_gas_price,omitempty"`  // Limite de gas price
-	Deadline      int64    `json:"deadline,omitempty"`       // Timestamp limite
-	ExpectedValue *big.Int `json:"expected_value,omitempty"` // Valor total esperado
+	MaxGasPrice   *big.Int `json:"max_gas_price,omitempty"`  // Limite de gas price: fee cap acima recusa a tx
+	Deadline      int64    `json:"deadline,omitempty"`       // Timestamp limite (unix, segundos)
+	ExpectedValue *big.Int `json:"expected_value,omitempty"` // Valor total esperado (soma dos Call.Value)
 }
 
 // DelegationService com validações
 type DelegationService struct {
 	ChainID *big.Int
 	RPC     EthClient
+
+	// DelegateAddress e TokenAddress são o SimpleDelegateContract e o Token usados
+	// pelas rotas; o padrão são os deploys da Holesky (DelegateContract, TokenContract)
+	DelegateAddress common.Address
+	TokenAddress    common.Address
+
+	// AllowUniversal aceita autorizações com chain_id = 0 (válidas em todas as chains).
+	// Desligado por padrão: quem obtiver a autorização pode reutilizá-la em outra chain.
+	AllowUniversal bool
+
+	// Store acompanha as autorizações emitidas/aceitas; AuthorizationTTL define a validade
+	// e AuthorizationRetention por quanto tempo registros fechados/expirados são mantidos
+	Store                  AuthorizationStore
+	AuthorizationTTL       time.Duration
+	AuthorizationRetention time.Duration
+
+	// GasMarginPercent é a margem somada à estimativa de gas (20 = +20%)
+	GasMarginPercent uint64
+	// BaseFeeMultiplier multiplica o base fee atual no GasFeeCap (mínimo 1)
+	BaseFeeMultiplier float64
+
+	// Nonces distribui os nonces dos sponsors entre requests concorrentes
+	Nonces *NonceManager
+	// Tracker acompanha as txs enviadas até a confirmação
+	Tracker *TxTracker
+}
+
+// NewDelegationService cria o serviço com store em memória e TTL padrão
+func NewDelegationService(chainID *big.Int, rpc EthClient) *DelegationService {
+	return &DelegationService{
+		ChainID:                chainID,
+		RPC:                    rpc,
+		DelegateAddress:        common.HexToAddress(DelegateContract),
+		TokenAddress:           common.HexToAddress(TokenContract),
+		Store:                  NewMemoryAuthorizationStore(),
+		AuthorizationTTL:       DefaultAuthorizationTTL,
+		AuthorizationRetention: DefaultAuthorizationRetention,
+		GasMarginPercent:       DefaultGasMarginPercent,
+		BaseFeeMultiplier:      DefaultBaseFeeMultiplier,
+		Nonces:                 NewNonceManager(rpc),
+		Tracker:                NewTxTracker(rpc, DefaultConfirmations),
+	}
 }
 
 // EthClient interface para interação com a blockchain
 type EthClient interface {
-	NonceAt(from common.Address) (uint64, error)
-	SuggestGasTipCap() (*big.Int, error)
-	SendTransaction(tx *types.Transaction) error
-	ChainID() (*big.Int, error)
+	NonceAt(ctx context.Context, from common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	ChainID(ctx context.Context) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address) ([]byte, error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	// CallContract executa eth_call no bloco mais recente; codeOverrides substitui o código das contas
+	CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	// CreateAccessList devolve a access list e o gas usado pela mensagem (eth_createAccessList)
+	CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error)
+	BalanceAt(ctx context.Context, account common.Address) (*big.Int, error)
+}
+
+// DelegationOptions controla como a autorização é assinada
+type DelegationOptions struct {
+	SelfSponsored bool // sender == authority: usa o nonce pending + 1 (a tx da authority vem antes)
+	Universal     bool // chain_id = 0: válida em TODAS as chains (replay cross-chain)
+}
+
+// SignDelegation com validações completas EIP-7702.
+// Assume que outra conta (sponsor) enviará a transação: usa o nonce atual do signer.
+func (d *DelegationService) SignDelegation(ctx context.Context, contractAddr common.Address, signer Signer) (*Authorization, error) {
+	return d.SignDelegationWithOptions(ctx, contractAddr, signer, DelegationOptions{})
 }
 
-// SignDelegation com validações completas EIP-7702
-func (d *DelegationService) SignDelegation(contractAddr common.Address, signerPK *ecdsa.PrivateKey) (*Authorization, error) {
+// SignSelfDelegation assina uma autorização para ser enviada pela própria authority.
+// A authority incrementa o nonce ao enviar a tx, então a autorização usa o nonce pending + 1.
+// Para executar pelo serviço prefira ExecuteSelf, que assina com o nonce reservado da tx.
+func (d *DelegationService) SignSelfDelegation(ctx context.Context, contractAddr common.Address, signer Signer) (*Authorization, error) {
+	return d.SignDelegationWithOptions(ctx, contractAddr, signer, DelegationOptions{SelfSponsored: true})
+}
+
+// SignDelegationWithOptions assina a autorização conforme opts
+func (d *DelegationService) SignDelegationWithOptions(ctx context.Context, contractAddr common.Address, signer Signer, opts DelegationOptions) (*Authorization, error) {
 	// VALIDAÇÕES CRÍTICAS
 	if d == nil || d.RPC == nil || d.ChainID == nil {
 		return nil, errors.New("service not properly initialized")
 	}
-	if signerPK == nil {
-		return nil, errors.New("signer private key is nil")
+	if signer == nil {
+		return nil, errors.New("signer is nil")
 	}
-	if (contractAddr == common.Address{}) {
-		return nil, errors.New("contract address is zero")
+	if err := d.checkDelegateContract(contractAddr); err != nil {
+		return nil, err
 	}
 
-	signer := crypto.PubkeyToAddress(signerPK.PublicKey)
+	return d.signAuthorization(ctx, contractAddr, signer, opts)
+}
+
+// Erros de contrato de delegação recusado
+var (
+	ErrZeroContract      = errors.New("contract address is zero")
+	ErrUntrustedContract = errors.New("unknown/untrusted contract")
+)
+
+// checkDelegateContract exige um contrato não-zero e conhecido
+func (d *DelegationService) checkDelegateContract(contractAddr common.Address) error {
+	if (contractAddr == common.Address{}) {
+		return ErrZeroContract
+	}
 
 	// Verificar se é um contrato conhecido (segurança)
 	if !d.isKnownContract(contractAddr) {
-		return nil, fmt.Errorf("unknown/untrusted contract: %s", contractAddr.Hex())
+		return fmt.Errorf("%w: %s", ErrUntrustedContract, contractAddr.Hex())
 	}
+	return nil
+}
 
-	// Obter nonce atual
-	nonce, err := d.RPC.NonceAt(signer)
+// authorizationParams retorna chain_id e nonce que a authority deve assinar.
+// Self-sponsored usa o nonce pending: a tx da authority entra depois das que já estão no mempool.
+func (d *DelegationService) authorizationParams(ctx context.Context, authority common.Address, opts DelegationOptions) (uint64, uint64, error) {
+	var nonce uint64
+	var err error
+	if opts.SelfSponsored {
+		nonce, err = d.RPC.PendingNonceAt(ctx, authority)
+		nonce++
+	} else {
+		nonce, err = d.RPC.NonceAt(ctx, authority)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce for %s: %w", signer.Hex(), err)
+		return 0, 0, fmt.Errorf("failed to get nonce for %s: %w", authority.Hex(), err)
 	}
+	return d.authorizationChainID(opts.Universal), nonce, nil
+}
 
-	// Criar mensagem EIP-7702: [chainId, address, nonce]
-	chainID := d.ChainID.Uint64()
-	authMessage := []interface{}{chainID, contractAddr, nonce}
+// authorizationChainID é o chain_id da autorização (0 = universal)
+func (d *DelegationService) authorizationChainID(universal bool) uint64 {
+	if universal {
+		return 0
+	}
+	return d.ChainID.Uint64()
+}
 
-	// RLP encode
-	encoded, err := rlp.EncodeToBytes(authMessage)
+// signAuthorization assina [chainId, contractAddr, nonce] sem checar o contrato.
+// contractAddr zero é a revogação da delegação.
+func (d *DelegationService) signAuthorization(ctx context.Context, contractAddr common.Address, signer Signer, opts DelegationOptions) (*Authorization, error) {
+	chainID, nonce, err := d.authorizationParams(ctx, signer.Address(), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode auth message: %w", err)
+		return nil, err
 	}
+	return d.signAuthorizationAt(ctx, contractAddr, signer, chainID, nonce)
+}
 
-	// Hash com magic byte 0x05
-	hash := crypto.Keccak256Hash(append([]byte{0x05}, encoded...))
+// signAuthorizationAt assina a autorização com chain_id e nonce já definidos
+func (d *DelegationService) signAuthorizationAt(ctx context.Context, contractAddr common.Address, signer Signer, chainID, nonce uint64) (*Authorization, error) {
+	authority := signer.Address()
+
+	// Criar mensagem EIP-7702: keccak(0x05 || rlp([chainId, address, nonce]))
+	hash, err := authorizationHash(chainID, contractAddr, nonce)
+	if err != nil {
+		return nil, err
+	}
 
 	// Assinar
-	signature, err := crypto.Sign(hash.Bytes(), signerPK)
+	signature, err := signer.SignHash(ctx, hash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign authorization: %w", err)
 	}
@@ -135,115 +292,723 @@ func (d *DelegationService) SignDelegation(contractAddr common.Address, signerPK
 	copy(r32[:], signature[:32])
 	copy(s32[:], signature[32:64])
 
-	return &Authorization{
+	auth := &Authorization{
 		ChainID:   chainID,
 		Address:   contractAddr,
 		Nonce:     nonce,
 		V:         v,
 		R:         r32,
 		S:         s32,
-		Signer:    signer,
+		Signer:    authority,
 		CreatedAt: time.Now().Unix(),
-	}, nil
+	}
+	if _, err := d.issueAuthorization(auth); err != nil {
+		return nil, err
+	}
+	return auth, nil
 }
 
-// ExecuteSponsored com validações de segurança completas
-func (d *DelegationService) ExecuteSponsored(auth *Authorization, calls []Call, sponsorPK *ecdsa.PrivateKey) (*types.Transaction, error) {
-	// VALIDAÇÕES DE SEGURANÇA EIP-7702
-	if err := d.validateAuthorization(auth); err != nil {
-		return nil, fmt.Errorf("invalid authorization: %w", err)
+// secp256k1HalfN é o limite superior de s aceito (EIP-2, sem maleabilidade)
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// Erros de verificação de assinatura da autorização
+var (
+	ErrInvalidYParity  = errors.New("invalid signature: v must be 0 or 1")
+	ErrHighS           = errors.New("invalid signature: s is in the upper half of the curve order")
+	ErrInvalidSigValue = errors.New("invalid signature: r or s out of range")
+	ErrSignerMismatch  = errors.New("recovered authority does not match signer")
+)
+
+// ErrUniversalNotAllowed indica autorização chain_id = 0 recusada pela política do servidor
+var ErrUniversalNotAllowed = errors.New("chain-agnostic authorizations (chain_id = 0) are disabled by server policy")
+
+// AuthorizationWarnings lista avisos sobre a autorização para devolver ao cliente
+func (d *DelegationService) AuthorizationWarnings(auth *Authorization) []string {
+	if auth == nil || auth.ChainID != 0 {
+		return nil
+	}
+
+	warnings := []string{
+		"chain_id = 0: this authorization is valid on EVERY EVM chain and can be replayed wherever the authority's nonce matches",
+		"anyone holding this authorization can delegate the account to " + auth.Address.Hex() + " on other chains",
+	}
+	if !d.AllowUniversal {
+		warnings = append(warnings, "this server does not accept chain-agnostic authorizations for sponsorship")
+	}
+	return warnings
+}
+
+// authorizationHash calcula keccak(0x05 || rlp([chain_id, address, nonce]))
+func authorizationHash(chainID uint64, contractAddr common.Address, nonce uint64) (common.Hash, error) {
+	encoded, err := rlp.EncodeToBytes([]interface{}{chainID, contractAddr, nonce})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode auth message: %w", err)
+	}
+	return crypto.Keccak256Hash(append([]byte{0x05}, encoded...)), nil
+}
+
+// SigningHash retorna o hash que a authority assina
+func (a *Authorization) SigningHash() (common.Hash, error) {
+	return authorizationHash(a.ChainID, a.Address, a.Nonce)
+}
+
+// RecoverAuthority recupera o endereço que assinou a autorização a partir de V/R/S.
+// Rejeita V diferente de 0 ou 1 e assinaturas com s alto.
+func (a *Authorization) RecoverAuthority() (common.Address, error) {
+	if a.V > 1 {
+		return common.Address{}, ErrInvalidYParity
+	}
+
+	r := new(big.Int).SetBytes(a.R[:])
+	s := new(big.Int).SetBytes(a.S[:])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		return common.Address{}, ErrHighS
+	}
+	if !crypto.ValidateSignatureValues(a.V, r, s, true) {
+		return common.Address{}, ErrInvalidSigValue
+	}
+
+	hash, err := a.SigningHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[:32], a.R[:])
+	copy(sig[32:64], a.S[:])
+	sig[64] = a.V
+
+	pub, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover authority: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// VerifyAuthorization confere se a assinatura da autorização pertence a auth.Signer
+func (d *DelegationService) VerifyAuthorization(auth *Authorization) error {
+	if auth == nil {
+		return errors.New("authorization is nil")
+	}
+
+	authority, err := auth.RecoverAuthority()
+	if err != nil {
+		return err
+	}
+	if authority != auth.Signer {
+		return fmt.Errorf("%w: recovered %s, signer %s", ErrSignerMismatch, authority.Hex(), auth.Signer.Hex())
+	}
+	return nil
+}
+
+// ExecuteSelf assina a delegação e a SetCodeTx com a mesma chave: a authority paga o próprio gas.
+// O nonce da tx é reservado antes e a autorização assina esse nonce + 1, mesmo com txs
+// da authority ainda pendentes.
+func (d *DelegationService) ExecuteSelf(ctx context.Context, contractAddr common.Address, calls []Call, signer Signer, universal bool, opts SponsorOptions) (*types.Transaction, *Authorization, *GasEstimate, error) {
+	if d == nil || d.RPC == nil || d.ChainID == nil {
+		return nil, nil, nil, errors.New("service not properly initialized")
+	}
+	if signer == nil {
+		return nil, nil, nil, errors.New("signer is nil")
+	}
+	if err := d.checkDelegateContract(contractAddr); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create authorization: %w", err)
+	}
+
+	txNonce, err := d.reserveNonce(ctx, signer.Address())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	auth, err := d.signAuthorizationAt(ctx, contractAddr, signer, d.authorizationChainID(universal), txNonce+1)
+	if err != nil {
+		d.Nonces.Release(signer.Address(), txNonce)
+		return nil, nil, nil, fmt.Errorf("failed to create authorization: %w", err)
 	}
+
+	tx, gas, err := d.executeSponsored(ctx, auth, calls, signer, opts, txNonce)
+	if err != nil {
+		d.Nonces.Release(signer.Address(), txNonce)
+		return nil, nil, nil, err
+	}
+	return tx, auth, gas, nil
+}
+
+// ExecuteSponsored com validações de segurança completas.
+// Se o sponsor for a própria authority, a autorização deve usar o nonce da tx + 1.
+// Devolve também a estimativa de gas usada (com o relatório da access list, se pedida).
+func (d *DelegationService) ExecuteSponsored(ctx context.Context, auth *Authorization, calls []Call, sponsor Signer, opts SponsorOptions) (*types.Transaction, *GasEstimate, error) {
+	if sponsor == nil {
+		return nil, nil, errors.New("sponsor signer is nil")
+	}
+
+	txNonce, err := d.reserveNonce(ctx, sponsor.Address())
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, gas, err := d.executeSponsored(ctx, auth, calls, sponsor, opts, txNonce)
+	if err != nil {
+		d.Nonces.Release(sponsor.Address(), txNonce)
+		return nil, nil, err
+	}
+	return tx, gas, nil
+}
+
+// executeSponsored valida, estima e assina a SetCodeTx no nonce já reservado
+func (d *DelegationService) executeSponsored(ctx context.Context, auth *Authorization, calls []Call, sponsor Signer, opts SponsorOptions, txNonce uint64) (_ *types.Transaction, _ *GasEstimate, err error) {
 	if err := d.validateCalls(calls); err != nil {
-		return nil, fmt.Errorf("invalid calls: %w", err)
+		return nil, nil, fmt.Errorf("invalid calls: %w", err)
+	}
+	// VALIDAÇÕES DE SEGURANÇA EIP-7702 (a autorização fica reservada para esta tx)
+	if err := d.validateAuthorization(ctx, auth, authorizationCheck{sender: sponsor.Address(), txNonce: txNonce, reserve: true}); err != nil {
+		return nil, nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, d.releaseReserved([]*Authorization{auth}))
+		}
+	}()
+	// Saldo e allowance da authority: uma falha certa não deve queimar gas do sponsor
+	if err := d.preflight(ctx, auth.Signer, calls); err != nil {
+		return nil, nil, err
+	}
+
+	// Simular antes de assinar: um revert não deve queimar gas do sponsor
+	if opts.Simulate {
+		if err := d.simulateSponsored(ctx, sponsor.Address(), auth, calls); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	sponsor := crypto.PubkeyToAddress(sponsorPK.PublicKey)
-	sponsorNonce, err := d.RPC.NonceAt(sponsor)
+	txData := buildCallData(calls)
+	auths := []*Authorization{auth}
+	gas, err := d.estimateSetCodeGas(ctx, sponsor.Address(), auth.Signer, auths, txData, calls)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sponsor nonce: %w", err)
+		return nil, nil, err
+	}
+	if opts.AccessList {
+		d.applyAccessList(ctx, gas, sponsor.Address(), auth.Signer, auths, txData)
 	}
 
-	// Gas configuration
-	tip, err := d.RPC.SuggestGasTipCap()
+	tx, err := d.buildSetCodeTx(ctx, auth.Signer, auths, txData, gas, sponsor, opts, txNonce)
 	if err != nil {
-		tip = big.NewInt(2_000_000_000) // fallback 2 Gwei
+		return nil, nil, err
 	}
+	return tx, gas, nil
+}
 
-	// Criar AuthList
+// reserveNonce reserva o próximo nonce do sponsor; quem não enviar a tx devolve com Release
+func (d *DelegationService) reserveNonce(ctx context.Context, sponsor common.Address) (uint64, error) {
+	if d.Nonces == nil {
+		return 0, errors.New("nonce manager not configured")
+	}
+	nonce, err := d.Nonces.Reserve(ctx, sponsor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve sponsor nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// buildCallData monta o calldata da tx: a call única direto, várias via execute
+func buildCallData(calls []Call) []byte {
+	if len(calls) == 1 {
+		return calls[0].Data
+	}
+	builder := &CallDataBuilder{}
+	executeData := builder.ExecuteCalls(calls)
+	return common.Hex2Bytes(strings.TrimPrefix(executeData, "0x"))
+}
+
+// MaxBatchAuthorizations limita o tamanho da AuthList de um lote
+const MaxBatchAuthorizations = 500
+
+// BatchAuthorizationResult é o resultado de cada authority em um lote
+type BatchAuthorizationResult struct {
+	Authority common.Address `json:"authority"`
+	Nonce     uint64         `json:"nonce"`
+	Included  bool           `json:"included"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// ExecuteSponsoredBatch aplica várias autorizações em uma única SetCodeTx patrocinada.
+// Cada autorização é validada (contrato confiável, assinatura, nonce, chain); as inválidas ficam de fora e
+// aparecem nos resultados com o motivo. A tx vai para o próprio sponsor, sem calldata.
+func (d *DelegationService) ExecuteSponsoredBatch(ctx context.Context, auths []*Authorization, sponsor Signer, opts SponsorOptions) (*types.Transaction, []BatchAuthorizationResult, error) {
+	if sponsor == nil {
+		return nil, nil, errors.New("sponsor signer is nil")
+	}
+	if len(auths) == 0 {
+		return nil, nil, errors.New("no authorizations provided")
+	}
+	if len(auths) > MaxBatchAuthorizations {
+		return nil, nil, fmt.Errorf("too many authorizations: %d (max %d)", len(auths), MaxBatchAuthorizations)
+	}
+	sponsorAddr := sponsor.Address()
+
+	txNonce, err := d.reserveNonce(ctx, sponsorAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, results, err := d.executeSponsoredBatch(ctx, auths, sponsor, opts, txNonce)
+	if err != nil {
+		d.Nonces.Release(sponsorAddr, txNonce)
+		return nil, results, err
+	}
+	return tx, results, nil
+}
+
+// executeSponsoredBatch filtra as autorizações e assina o lote no nonce já reservado
+func (d *DelegationService) executeSponsoredBatch(ctx context.Context, auths []*Authorization, sponsor Signer, opts SponsorOptions, txNonce uint64) (_ *types.Transaction, _ []BatchAuthorizationResult, err error) {
+	sponsorAddr := sponsor.Address()
+	results := make([]BatchAuthorizationResult, len(auths))
+	included := make([]*Authorization, 0, len(auths))
+	seen := make(map[common.Address]bool, len(auths))
+
+	for i, auth := range auths {
+		if auth == nil {
+			results[i].Error = "authorization is nil"
+			continue
+		}
+		results[i].Authority = auth.Signer
+		results[i].Nonce = auth.Nonce
+
+		// Uma authority só pode aparecer uma vez: a segunda tupla teria nonce inválido
+		if seen[auth.Signer] {
+			results[i].Error = "duplicate authority in batch"
+			continue
+		}
+		if err := d.validateAuthorization(ctx, auth, authorizationCheck{sender: sponsorAddr, txNonce: txNonce, reserve: true}); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		seen[auth.Signer] = true
+		results[i].Included = true
+		included = append(included, auth)
+	}
+
+	if len(included) == 0 {
+		return nil, results, errors.New("no valid authorizations in batch")
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, d.releaseReserved(included))
+		}
+	}()
+
+	gas, err := d.estimateSetCodeGas(ctx, sponsorAddr, sponsorAddr, included, nil, nil)
+	if err != nil {
+		return nil, results, err
+	}
+	tx, err := d.buildSetCodeTx(ctx, sponsorAddr, included, nil, gas, sponsor, opts, txNonce)
+	if err != nil {
+		return nil, results, err
+	}
+	return tx, results, nil
+}
+
+// RevokeDelegation assina uma autorização para o endereço zero e a SetCodeTx patrocinada
+// que a aplica, limpando o código da authority. É o único caminho que aceita o endereço zero.
+func (d *DelegationService) RevokeDelegation(ctx context.Context, signer, sponsor Signer, opts SponsorOptions) (*types.Transaction, *Authorization, error) {
+	if d == nil || d.RPC == nil || d.ChainID == nil {
+		return nil, nil, errors.New("service not properly initialized")
+	}
+	if signer == nil || sponsor == nil {
+		return nil, nil, errors.New("signer or sponsor is nil")
+	}
+
+	txNonce, err := d.reserveNonce(ctx, sponsor.Address())
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, auth, err := d.revokeDelegation(ctx, signer, sponsor, opts, txNonce)
+	if err != nil {
+		d.Nonces.Release(sponsor.Address(), txNonce)
+		return nil, nil, err
+	}
+	return tx, auth, nil
+}
+
+// RevokeWithAuthorization envia numa SetCodeTx patrocinada uma revogação assinada no
+// cliente (autorização para o endereço zero, ex.: via /authorization/prepare)
+func (d *DelegationService) RevokeWithAuthorization(ctx context.Context, auth *Authorization, sponsor Signer, opts SponsorOptions) (*types.Transaction, error) {
+	if d == nil || d.RPC == nil || d.ChainID == nil {
+		return nil, errors.New("service not properly initialized")
+	}
+	if auth == nil || sponsor == nil {
+		return nil, errors.New("authorization or sponsor is nil")
+	}
+	if (auth.Address != common.Address{}) {
+		return nil, fmt.Errorf("authorization delegates to %s, not to the zero address", auth.Address.Hex())
+	}
+
+	txNonce, err := d.reserveNonce(ctx, sponsor.Address())
+	if err != nil {
+		return nil, err
+	}
+	tx, err := d.buildRevocation(ctx, auth, sponsor, opts, txNonce)
+	if err != nil {
+		d.Nonces.Release(sponsor.Address(), txNonce)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// revokeDelegation assina a revogação e a SetCodeTx no nonce já reservado
+func (d *DelegationService) revokeDelegation(ctx context.Context, signer, sponsor Signer, opts SponsorOptions, txNonce uint64) (*types.Transaction, *Authorization, error) {
+	var auth *Authorization
+	var err error
+	if sponsor.Address() == signer.Address() {
+		// A própria authority envia: a autorização vem depois da tx, no nonce seguinte
+		auth, err = d.signAuthorizationAt(ctx, common.Address{}, signer, d.ChainID.Uint64(), txNonce+1)
+	} else {
+		auth, err = d.signAuthorization(ctx, common.Address{}, signer, DelegationOptions{})
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create revocation: %w", err)
+	}
+	tx, err := d.buildRevocation(ctx, auth, sponsor, opts, txNonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, auth, nil
+}
+
+// buildRevocation valida a revogação e monta a SetCodeTx que só aplica a AuthList
+func (d *DelegationService) buildRevocation(ctx context.Context, auth *Authorization, sponsor Signer, opts SponsorOptions, txNonce uint64) (_ *types.Transaction, err error) {
+	if err := d.validateAuthorization(ctx, auth, authorizationCheck{sender: sponsor.Address(), txNonce: txNonce, revocation: true, reserve: true}); err != nil {
+		return nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, d.releaseReserved([]*Authorization{auth}))
+		}
+	}()
+
+	// Sem calldata: a tx só aplica a AuthList
+	auths := []*Authorization{auth}
+	gas, err := d.estimateSetCodeGas(ctx, sponsor.Address(), auth.Signer, auths, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.buildSetCodeTx(ctx, auth.Signer, auths, nil, gas, sponsor, opts, txNonce)
+}
+
+// ConfirmRevocation verifica que o código da authority voltou a ser vazio
+func (d *DelegationService) ConfirmRevocation(ctx context.Context, authority common.Address) error {
+	code, err := d.RPC.CodeAt(ctx, authority)
+	if err != nil {
+		return fmt.Errorf("failed to get code for %s: %w", authority.Hex(), err)
+	}
+	if len(code) != 0 {
+		return fmt.Errorf("account %s still has code: 0x%x", authority.Hex(), code)
+	}
+	return nil
+}
+
+// DelegationInfo descreve o estado de delegação de uma conta
+type DelegationInfo struct {
+	Address    common.Address  `json:"address"`
+	Delegated  bool            `json:"delegated"`          // código é 0xef0100 || address
+	Delegate   *common.Address `json:"delegate,omitempty"` // contrato delegado
+	Trusted    bool            `json:"trusted"`            // delegado está na lista de contratos conhecidos
+	IsContract bool            `json:"is_contract"`        // código comum (não é designator)
+	Code       string          `json:"code"`
+}
+
+// DelegationStatus lê o código da conta e interpreta o designator 0xef0100 || address
+func (d *DelegationService) DelegationStatus(ctx context.Context, addr common.Address) (*DelegationInfo, error) {
+	code, err := d.RPC.CodeAt(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code for %s: %w", addr.Hex(), err)
+	}
+
+	info := &DelegationInfo{
+		Address: addr,
+		Code:    "0x" + common.Bytes2Hex(code),
+	}
+	if delegate, ok := types.ParseDelegation(code); ok {
+		info.Delegated = true
+		info.Delegate = &delegate
+		info.Trusted = d.isKnownContract(delegate)
+	} else if len(code) > 0 {
+		info.IsContract = true
+	}
+	return info, nil
+}
+
+// WaitMined aguarda o recibo da transação fazendo polling até timeout
+func (d *DelegationService) WaitMined(ctx context.Context, hash common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		receipt, err := d.RPC.TransactionReceipt(ctx, hash)
+		if err == nil && receipt != nil {
+			return receipt, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("transaction %s not mined after %s", hash.Hex(), timeout)
+		}
+		// Para de esperar se o cliente desistir do request
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// toSetCodeAuthorization converte para a tupla usada na AuthList
+func (a *Authorization) toSetCodeAuthorization() types.SetCodeAuthorization {
 	var r, s uint256.Int
-	r.SetBytes(auth.R[:])
-	s.SetBytes(auth.S[:])
-
-	authList := []types.SetCodeAuthorization{{
-		ChainID: *uint256.NewInt(auth.ChainID),
-		Address: auth.Address,
-		Nonce:   auth.Nonce,
-		V:       auth.V,
+	r.SetBytes(a.R[:])
+	s.SetBytes(a.S[:])
+
+	return types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(a.ChainID),
+		Address: a.Address,
+		Nonce:   a.Nonce,
+		V:       a.V,
 		R:       r,
 		S:       s,
-	}}
+	}
+}
 
-	// Construir call data
-	var txData []byte
-	var gasLimit uint64 = 1_000_000 // default
+// fromSetCodeAuthorization converte a tupla da AuthList de volta para Authorization
+func fromSetCodeAuthorization(sa types.SetCodeAuthorization) *Authorization {
+	auth := &Authorization{
+		ChainID: sa.ChainID.Uint64(),
+		Address: sa.Address,
+		Nonce:   sa.Nonce,
+		V:       sa.V,
+		R:       sa.R.Bytes32(),
+		S:       sa.S.Bytes32(),
+	}
+	if authority, err := sa.Authority(); err == nil {
+		auth.Signer = authority
+	}
+	return auth
+}
 
-	if len(calls) == 1 {
-		txData = calls[0].Data
-		if calls[0].GasLimit > 0 {
-			gasLimit = calls[0].GasLimit
-		}
-	} else {
-		// Usar execute para múltiplas calls
-		builder := &CallDataBuilder{}
-		executeData := builder.ExecuteCalls(calls)
-		txData = common.Hex2Bytes(strings.TrimPrefix(executeData, "0x"))
-		gasLimit = d.calculateMulticallGas(calls)
+// buildSetCodeTx monta e assina a SetCodeTx do sponsor no nonce reservado por quem chama
+// (que também o devolve se a assinatura falhar)
+func (d *DelegationService) buildSetCodeTx(ctx context.Context, to common.Address, auths []*Authorization, txData []byte, gas *GasEstimate, sponsor Signer, opts SponsorOptions, sponsorNonce uint64) (*types.Transaction, error) {
+	// EIP-1559: base fee atual * multiplicador + gorjeta, limitado pelo request
+	fees, err := d.feesFor(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Criar AuthList
+	authList := make([]types.SetCodeAuthorization, len(auths))
+	for i, auth := range auths {
+		authList[i] = auth.toSetCodeAuthorization()
+	}
+
+	// Access list só quando anexada pela comparação de gas (opt-in)
+	var accessList types.AccessList
+	if gas.AccessList != nil && gas.AccessList.Attached {
+		accessList = gas.AccessList.List
 	}
 
 	// Criar SetCodeTx
 	setCodeTx := &types.SetCodeTx{
-		ChainID:   uint256.MustFromBig(d.ChainID),
-		Nonce:     sponsorNonce,
-		GasTipCap: uint256.MustFromBig(tip),
-		GasFeeCap: uint256.MustFromBig(new(big.Int).Mul(tip, big.NewInt(3))),
-		Gas:       gasLimit,
-		To:        auth.Signer,       // SEMPRE o signer (authority)
-		Value:     uint256.NewInt(0), // ✅ SEMPRE 0 - sponsor só paga gas
-		Data:      txData,
-		AuthList:  authList,
+		ChainID:    uint256.MustFromBig(d.ChainID),
+		Nonce:      sponsorNonce,
+		GasTipCap:  uint256.MustFromBig(fees.GasTipCap),
+		GasFeeCap:  uint256.MustFromBig(fees.GasFeeCap),
+		Gas:        gas.Limit,
+		To:         to,                // authority (ou o próprio sponsor em lotes)
+		Value:      uint256.NewInt(0), // ✅ SEMPRE 0 - sponsor só paga gas
+		Data:       txData,
+		AccessList: accessList,
+		AuthList:   authList,
+	}
+
+	// Assinar transação; o nonce reservado só é consumido por SendTransaction
+	return sponsor.SignTx(ctx, types.NewTx(setCodeTx), d.ChainID)
+}
+
+// SendTransaction envia a tx, confirma o nonce do sponsor e registra a tx nas
+// autorizações que ela carrega. Se o envio falhar, o nonce volta para a fila e
+// a conta é ressincronizada com o node.
+func (d *DelegationService) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(types.LatestSignerForChainID(d.ChainID), tx)
+	if err != nil {
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+
+	// Depois de assinada a tx segue mesmo que o cliente desista: cancelar no meio do
+	// envio deixaria o nonce em estado incerto (o timeout do RPC continua valendo)
+	ctx = context.WithoutCancel(ctx)
+
+	// As autorizações apontam para a tx antes do envio: nenhuma outra tx as usa enquanto
+	// esta pode chegar ao mempool
+	if err := d.markSubmitted(tx); err != nil {
+		d.DiscardTransaction(tx)
+		return err
+	}
+
+	// "already known": a tx já está no mempool, então o nonce foi usado
+	if err := ClassifyRPCError(d.RPC.SendTransaction(ctx, tx)); err != nil && !errors.Is(err, ErrTxAlreadyKnown) {
+		if d.Nonces != nil {
+			d.Nonces.Release(from, tx.Nonce())
+			d.Nonces.Resync(ctx, from)
+		}
+		if relErr := d.releaseTransaction(tx); relErr != nil {
+			return errors.Join(err, relErr)
+		}
+		return err
+	}
+
+	if d.Nonces != nil {
+		d.Nonces.Commit(from, tx.Nonce(), tx.Hash())
+	}
+	if d.Tracker != nil {
+		d.Tracker.Track(tx, from)
+	}
+	return nil
+}
+
+// PollTransactions atualiza o tracker. Txs descartadas disparam resync do nonce do
+// sponsor e liberam as autorizações que levavam; numa substituída as autorizações
+// passam para a tx da cadeia que foi minerada.
+func (d *DelegationService) PollTransactions(ctx context.Context) error {
+	if d.Tracker == nil {
+		return errors.New("transaction tracker not configured")
+	}
+	unmined, err := d.Tracker.Poll(ctx)
+	if err != nil {
+		return err
 	}
+	var errs []error
+	for _, tracked := range unmined {
+		if tracked.tx == nil {
+			continue
+		}
+		if tracked.Status == TxReplaced {
+			if tracked.ReplacedBy != nil {
+				if err := d.repointSubmitted(tracked.tx, tracked.ReplacedBy); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if d.Nonces != nil {
+			d.Nonces.Resync(ctx, tracked.From)
+		}
+		if err := d.releaseSubmitted(tracked.tx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TransactionStatus devolve status, bloco, gas e logs decodificados de uma tx
+func (d *DelegationService) TransactionStatus(ctx context.Context, hash common.Hash) (*TrackedTx, error) {
+	if d.Tracker == nil {
+		return nil, errors.New("transaction tracker not configured")
+	}
+	return d.Tracker.Get(ctx, hash)
+}
 
-	// Assinar transação
-	signer := types.NewPragueSigner(d.ChainID)
-	return types.SignNewTx(sponsorPK, signer, setCodeTx)
+// HandOffTransaction entrega uma tx assinada para envio externo (broadcast: false): o
+// nonce fica pendente e as autorizações presas à tx, como se o serviço a tivesse enviado.
+// Se ninguém enviar, o nonce volta a ser usado depois de externalNonceHold.
+func (d *DelegationService) HandOffTransaction(tx *types.Transaction) error {
+	from, err := types.Sender(types.LatestSignerForChainID(d.ChainID), tx)
+	if err != nil {
+		d.DiscardTransaction(tx)
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+	if err := d.markSubmitted(tx); err != nil {
+		d.DiscardTransaction(tx)
+		return err
+	}
+	if d.Nonces != nil {
+		d.Nonces.CommitExternal(from, tx.Nonce(), tx.Hash(), time.Now().Add(externalNonceHold))
+	}
+	return nil
+}
+
+// DiscardTransaction devolve o nonce e as autorizações de uma tx assinada que não será
+// enviada. Se o store falhar, a reserva das autorizações expira sozinha.
+func (d *DelegationService) DiscardTransaction(tx *types.Transaction) {
+	d.releaseTransaction(tx)
+	if d.Nonces == nil {
+		return
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(d.ChainID), tx); err == nil {
+		d.Nonces.Release(from, tx.Nonce())
+	}
 }
 
-// Validações de segurança conforme EIP-7702
-func (d *DelegationService) validateAuthorization(auth *Authorization) error {
+// authorizationCheck descreve a SetCodeTx em que a autorização vai entrar
+type authorizationCheck struct {
+	sender     common.Address // quem envia a SetCodeTx
+	txNonce    uint64         // nonce da SetCodeTx; conta quando sender == authority
+	revocation bool           // caminho de revogação: aceita Address zero
+	reserve    bool           // marca a autorização como submitting (quem reserva libera se não enviar)
+}
+
+// Validações de segurança conforme EIP-7702.
+// Quando check.sender == authority o nonce esperado é check.txNonce + 1 (o nonce da
+// authority sobe com a tx antes da AuthList).
+func (d *DelegationService) validateAuthorization(ctx context.Context, auth *Authorization, check authorizationCheck) error {
 	if auth == nil {
 		return errors.New("authorization is nil")
 	}
 
-	// Verificar idade da autorização (replay protection)
-	if time.Now().Unix()-auth.CreatedAt > 300 { // 5 minutos
-		return errors.New("authorization too old")
+	// Verificar assinatura: a authority recuperada deve ser o signer declarado
+	if err := d.VerifyAuthorization(auth); err != nil {
+		return err
+	}
+
+	// Só contratos confiáveis; o endereço zero só vale para revogar
+	if !check.revocation || auth.Address != (common.Address{}) {
+		if err := d.checkDelegateContract(auth.Address); err != nil {
+			return err
+		}
 	}
 
-	// Verificar chain ID
-	if auth.ChainID != d.ChainID.Uint64() {
+	// Verificar chain ID (0 = universal, somente se a política permitir)
+	if auth.ChainID == 0 {
+		if !d.AllowUniversal {
+			return ErrUniversalNotAllowed
+		}
+	} else if auth.ChainID != d.ChainID.Uint64() {
 		return errors.New("chain ID mismatch")
 	}
 
 	// Verificar nonce atual
-	currentNonce, err := d.RPC.NonceAt(auth.Signer)
+	currentNonce, err := d.RPC.NonceAt(ctx, auth.Signer)
 	if err != nil {
 		return fmt.Errorf("failed to check current nonce: %w", err)
 	}
-	if auth.Nonce != currentNonce {
-		return fmt.Errorf("nonce mismatch: expected %d, got %d", currentNonce, auth.Nonce)
+	if currentNonce > auth.Nonce {
+		// A authority já usou este nonce: a autorização nunca mais será válida
+		if err := d.settleTracked(ctx, auth); err != nil {
+			return fmt.Errorf("failed to update authorization state: %w", err)
+		}
+		return fmt.Errorf("nonce mismatch: authorization nonce %d already used (current %d)", auth.Nonce, currentNonce)
+	}
+	expectedNonce := currentNonce
+	if check.sender == auth.Signer {
+		expectedNonce = check.txNonce + 1 // o nonce da authority é incrementado antes de processar a AuthList
+	}
+	if auth.Nonce != expectedNonce {
+		return fmt.Errorf("nonce mismatch: expected %d, got %d", expectedNonce, auth.Nonce)
 	}
 
-	return nil
+	// Ciclo de vida (replay protection): estado e expiração controlados pelo servidor,
+	// nunca pelo CreatedAt enviado pelo cliente. Só autorizações válidas são registradas.
+	rec, err := d.trackAuthorization(auth)
+	if err != nil {
+		return err
+	}
+	if check.reserve {
+		// Conferir e reservar no mesmo passo do store: sem janela para outro request
+		return d.reserveAuthorization(auth)
+	}
+	return checkAuthorizationRecord(rec)
 }
 
 func (d *DelegationService) validateCalls(calls []Call) error {
@@ -251,17 +1016,14 @@ func (d *DelegationService) validateCalls(calls []Call) error {
 		return errors.New("no calls provided")
 	}
 
-	totalValue := big.NewInt(0)
 	for i, call := range calls {
 		if (call.To == common.Address{}) {
 			return fmt.Errorf("call %d has zero address", i)
 		}
-		if call.Value != nil {
-			totalValue.Add(totalValue, call.Value)
-		}
 	}
 
 	// Verificar valor total (protection contra malicious sponsor)
+	totalValue := totalCallValue(calls)
 	maxValue := new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)) // 10 ETH max
 	if totalValue.Cmp(maxValue) > 0 {
 		return fmt.Errorf("total value too high: %s", totalValue.String())
@@ -271,15 +1033,5 @@ func (d *DelegationService) validateCalls(calls []Call) error {
 }
 
 func (d *DelegationService) isKnownContract(addr common.Address) bool {
-	knownContracts := map[common.Address]bool{
-		common.HexToAddress(TokenContract):    true,
-		common.HexToAddress(DelegateContract): true,
-	}
-	return knownContracts[addr]
-}
-
-func (d *DelegationService) calculateMulticallGas(calls []Call) uint64 {
-	baseGas := uint64(100_000)   // base para execute
-	perCallGas := uint64(50_000) // gas por call
-	return baseGas + uint64(len(calls))*perCallGas
+	return addr == d.TokenAddress || addr == d.DelegateAddress
 }
@@ -0,0 +1,445 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/omnes/eip7702/eip7702/fakechain"
+)
+
+var testChainID = big.NewInt(1337)
+
+// testEnv é o serviço ligado a uma fakechain com um sponsor financiado
+type testEnv struct {
+	chain   *fakechain.Chain
+	svc     *DelegationService
+	sponsor *KeySigner
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	chain := fakechain.New(testChainID)
+	sponsor := newTestSigner(t)
+	chain.Fund(sponsor.Address(), big.NewInt(params.Ether))
+	return &testEnv{chain: chain, svc: NewDelegationService(testChainID, chain), sponsor: sponsor}
+}
+
+func newTestSigner(t *testing.T) *KeySigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewKeySigner(key)
+}
+
+// mintCall é SimpleDelegateContract.mint executado na própria authority
+func (e *testEnv) mintCall(authority common.Address) Call {
+	cd := (&CallDataBuilder{}).Mint(e.svc.TokenAddress, authority, big.NewInt(1))
+	return Call{To: authority, Data: common.FromHex(cd)}
+}
+
+// sponsorDelegation assina, executa e envia a delegação de authority para o DelegateAddress
+func (e *testEnv) sponsorDelegation(t *testing.T, authority Signer) (*types.Transaction, *Authorization) {
+	t.Helper()
+	ctx := context.Background()
+	auth, err := e.svc.SignDelegation(ctx, e.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatalf("sign delegation: %v", err)
+	}
+	tx, _, err := e.svc.ExecuteSponsored(ctx, auth, []Call{e.mintCall(auth.Signer)}, e.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatalf("execute sponsored: %v", err)
+	}
+	if err := e.svc.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	return tx, auth
+}
+
+func (e *testEnv) requireDelegated(t *testing.T, authority, delegate common.Address) {
+	t.Helper()
+	code, _ := e.chain.CodeAt(context.Background(), authority)
+	if !bytes.Equal(code, types.AddressToDelegation(delegate)) {
+		t.Fatalf("code of %s = %x, want delegation to %s", authority, code, delegate)
+	}
+}
+
+func TestExecuteSponsored(t *testing.T) {
+	env := newTestEnv(t)
+	authority := newTestSigner(t)
+
+	tx, auth := env.sponsorDelegation(t, authority)
+
+	env.requireDelegated(t, authority.Address(), env.svc.DelegateAddress)
+	if tx.Type() != types.SetCodeTxType || *tx.To() != authority.Address() {
+		t.Fatalf("tx type %d to %s", tx.Type(), tx.To())
+	}
+	if tx.Value().Sign() != 0 {
+		t.Fatalf("sponsored tx carries value %s", tx.Value())
+	}
+	receipt, err := env.chain.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("receipt = %+v, err %v", receipt, err)
+	}
+
+	// A tx minerada consome a autorização
+	if err := env.svc.SyncAuthorizations(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.State != AuthorizationConsumed || rec.TxHash == nil || *rec.TxHash != tx.Hash() {
+		t.Fatalf("record = %+v, want consumed by %s", rec, tx.Hash())
+	}
+}
+
+func TestExecuteSponsoredRejectsReplay(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	_, auth := env.sponsorDelegation(t, newTestSigner(t))
+
+	_, _, err := env.svc.ExecuteSponsored(context.Background(), auth, []Call{env.mintCall(auth.Signer)}, env.sponsor, SponsorOptions{})
+	if err == nil || !strings.Contains(err.Error(), "already submitted") {
+		t.Fatalf("err = %v, want already submitted", err)
+	}
+}
+
+func TestExecuteSponsoredRejectsInvalidAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := []Call{env.mintCall(authority.Address())}
+
+	forged := *auth
+	forged.Signer = newTestSigner(t).Address()
+	if _, _, err := env.svc.ExecuteSponsored(ctx, &forged, calls, env.sponsor, SponsorOptions{}); !errors.Is(err, ErrSignerMismatch) {
+		t.Fatalf("forged signer: err = %v", err)
+	}
+
+	// O nonce da authority avançou: a autorização não vale mais
+	env.chain.SetNonce(authority.Address(), 1)
+	if _, _, err := env.svc.ExecuteSponsored(ctx, auth, calls, env.sponsor, SponsorOptions{}); err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+		t.Fatalf("stale nonce: err = %v", err)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.State != AuthorizationInvalidated {
+		t.Fatalf("state = %s, want invalidated", rec.State)
+	}
+
+	// Nada foi enviado
+	if nonce, _ := env.chain.NonceAt(ctx, env.sponsor.Address()); nonce != 0 {
+		t.Fatalf("sponsor nonce = %d, want 0", nonce)
+	}
+}
+
+func TestExecuteSponsoredRejectsUntrustedContract(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+
+	for contract, want := range map[common.Address]error{
+		{0xde, 0xad}: ErrUntrustedContract,
+		{}:           ErrZeroContract, // revogação só pelo RevokeDelegation
+	} {
+		auth, err := env.svc.signAuthorization(ctx, contract, authority, DelegationOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{}); !errors.Is(err, want) {
+			t.Errorf("delegate %s: err = %v, want %v", contract, err, want)
+		}
+	}
+	if st := env.svc.Nonces.Status(env.sponsor.Address()); st.Next != 0 || len(st.Reserved) != 0 {
+		t.Fatalf("nonce status after rejection = %+v", st)
+	}
+}
+
+func TestExecuteSelf(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	env.chain.Fund(authority.Address(), big.NewInt(params.Ether))
+
+	tx, auth, _, err := env.svc.ExecuteSelf(ctx, env.svc.DelegateAddress, []Call{env.mintCall(authority.Address())}, authority, false, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Nonce != tx.Nonce()+1 {
+		t.Fatalf("authorization nonce %d, tx nonce %d", auth.Nonce, tx.Nonce())
+	}
+	if err := env.svc.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	env.requireDelegated(t, authority.Address(), env.svc.DelegateAddress)
+	if nonce, _ := env.chain.NonceAt(ctx, authority.Address()); nonce != 2 {
+		t.Fatalf("authority nonce = %d, want 2", nonce)
+	}
+}
+
+func TestExecuteSelfWithPendingTx(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	env.chain.Fund(authority.Address(), big.NewInt(params.Ether))
+
+	// A authority já tem uma tx no mempool: a SetCodeTx entra no nonce 1 e a autorização no 2
+	pending, err := authority.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   testChainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Mul(env.chain.BaseFee, big.NewInt(2)),
+		Gas:       params.TxGas,
+		To:        &common.Address{1},
+	}), testChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.chain.SendTransaction(ctx, pending); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, auth, _, err := env.svc.ExecuteSelf(ctx, env.svc.DelegateAddress, []Call{env.mintCall(authority.Address())}, authority, false, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 1 || auth.Nonce != 2 {
+		t.Fatalf("tx nonce %d, authorization nonce %d; want 1 and 2", tx.Nonce(), auth.Nonce)
+	}
+	if err := env.svc.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	env.chain.Mine()
+	env.requireDelegated(t, authority.Address(), env.svc.DelegateAddress)
+}
+
+func TestExecuteSponsoredBatch(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	var auths []*Authorization
+	var authorities []common.Address
+	for i := 0; i < 2; i++ {
+		authority := newTestSigner(t)
+		auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auths = append(auths, auth)
+		authorities = append(authorities, authority.Address())
+	}
+	stale := newTestSigner(t)
+	staleAuth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.chain.SetNonce(stale.Address(), 7)
+
+	// Assinaturas válidas para um contrato fora da lista e para o zero (revogação)
+	var untrusted []*Authorization
+	for _, contract := range []common.Address{{0xde, 0xad}, {}} {
+		auth, err := env.svc.signAuthorization(ctx, contract, newTestSigner(t), DelegationOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		untrusted = append(untrusted, auth)
+	}
+	auths = append(auths, auths[0], staleAuth, nil, untrusted[0], untrusted[1])
+
+	tx, results, err := env.svc.ExecuteSponsoredBatch(ctx, auths, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tx.SetCodeAuthorizations()); got != 2 {
+		t.Fatalf("auth list has %d tuples, want 2", got)
+	}
+	wantErr := []string{"", "", "duplicate authority", "nonce mismatch", "authorization is nil", "untrusted contract", "contract address is zero"}
+	for i, want := range wantErr {
+		r := results[i]
+		if r.Included != (want == "") || !strings.Contains(r.Error, want) {
+			t.Errorf("result %d = %+v, want error %q", i, r, want)
+		}
+	}
+
+	if err := env.svc.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	for _, authority := range authorities {
+		env.requireDelegated(t, authority, env.svc.DelegateAddress)
+	}
+}
+
+func TestRevokeDelegation(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	env.sponsorDelegation(t, authority)
+
+	tx, auth, err := env.svc.RevokeDelegation(ctx, authority, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Address != (common.Address{}) {
+		t.Fatalf("revocation delegates to %s", auth.Address)
+	}
+	if err := env.svc.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.svc.ConfirmRevocation(ctx, authority.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelegationStatus(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+
+	info, err := env.svc.DelegationStatus(ctx, authority.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Delegated || info.IsContract {
+		t.Fatalf("fresh EOA reported as %+v", info)
+	}
+
+	env.sponsorDelegation(t, authority)
+	info, err = env.svc.DelegationStatus(ctx, authority.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Delegated || !info.Trusted || *info.Delegate != env.svc.DelegateAddress {
+		t.Fatalf("delegated EOA reported as %+v", info)
+	}
+}
+
+func TestSendTransactionReleasesNonceOnRejection(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// O node recusa: o nonce reservado volta para a fila
+	env.chain.BaseFee = new(big.Int).Mul(tx.GasFeeCap(), big.NewInt(2))
+	if err := env.svc.SendTransaction(ctx, tx); !errors.Is(err, ErrFeeTooLow) {
+		t.Fatalf("err = %v, want ErrFeeTooLow", err)
+	}
+	if st := env.svc.Nonces.Status(env.sponsor.Address()); st.Next != 0 || len(st.Reserved) != 0 {
+		t.Fatalf("nonce status after rejection = %+v", st)
+	}
+}
+
+// signedAuthorization assina a tupla com types.SignSetCode, como uma wallet faria
+func signedAuthorization(t *testing.T, chainID uint64, contract common.Address, nonce uint64) (*Authorization, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(chainID),
+		Address: contract,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Authorization{
+		ChainID: chainID,
+		Address: contract,
+		Nonce:   nonce,
+		V:       signed.V,
+		R:       signed.R.Bytes32(),
+		S:       signed.S.Bytes32(),
+		Signer:  crypto.PubkeyToAddress(key.PublicKey),
+	}, key
+}
+
+func TestAuthorizationMatchesSignSetCode(t *testing.T) {
+	auth, key := signedAuthorization(t, 1337, common.Address{0xde, 0xad}, 5)
+
+	authority, err := auth.RecoverAuthority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authority != auth.Signer {
+		t.Fatalf("recovered %s, want %s", authority, auth.Signer)
+	}
+	if err := (&DelegationService{}).VerifyAuthorization(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	// O hash assinado aqui é o mesmo que a geth assina (RFC 6979: mesma assinatura)
+	hash, err := auth.SigningHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig[:32], auth.R[:]) || !bytes.Equal(sig[32:64], auth.S[:]) || sig[64] != auth.V {
+		t.Fatal("signature over SigningHash differs from types.SignSetCode")
+	}
+}
+
+func TestVerifyAuthorizationRejects(t *testing.T) {
+	valid, _ := signedAuthorization(t, 1337, common.Address{0xde, 0xad}, 5)
+
+	tests := []struct {
+		name   string
+		mutate func(a *Authorization)
+		want   error
+	}{
+		{"valid", func(a *Authorization) {}, nil},
+		{"v = 2", func(a *Authorization) { a.V = 2 }, ErrInvalidYParity},
+		{"legacy v = 27", func(a *Authorization) { a.V = 27 }, ErrInvalidYParity},
+		{"high s", func(a *Authorization) {
+			// (r, n - s, 1 - v) é a mesma assinatura maleável
+			s := new(big.Int).SetBytes(a.S[:])
+			a.S = common.BigToHash(new(big.Int).Sub(crypto.S256().Params().N, s))
+			a.V ^= 1
+		}, ErrHighS},
+		{"zero r", func(a *Authorization) { a.R = [32]byte{} }, ErrInvalidSigValue},
+		{"other signer", func(a *Authorization) { a.Signer = common.Address{0xbe, 0xef} }, ErrSignerMismatch},
+		{"tampered nonce", func(a *Authorization) { a.Nonce++ }, ErrSignerMismatch},
+		{"tampered contract", func(a *Authorization) { a.Address = common.Address{0xba, 0xd} }, ErrSignerMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := *valid
+			tt.mutate(&auth)
+			err := (&DelegationService{}).VerifyAuthorization(&auth)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
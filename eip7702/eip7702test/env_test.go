@@ -0,0 +1,200 @@
+package eip7702test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/omnes/eip7702/eip7702"
+)
+
+// post envia o request ao servidor do env, exige 200 e devolve o receipt da tx minerada
+func post(t *testing.T, env *Env, route string, body interface{}) *types.Receipt {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(env.Server.URL+route, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST %s -> %d: %s", route, resp.StatusCode, raw)
+	}
+
+	var out struct {
+		TxHash common.Hash `json:"tx_hash"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	receipt, err := env.Client.TransactionReceipt(context.Background(), out.TxHash)
+	if err != nil {
+		t.Fatalf("receipt of %s: %v", out.TxHash, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("tx %s reverted", out.TxHash)
+	}
+	return receipt
+}
+
+// requireDelegated confere o designator da authority para o SimpleDelegateContract
+func requireDelegated(t *testing.T, env *Env, authority common.Address) {
+	t.Helper()
+	code, err := env.Client.CodeAt(context.Background(), authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, types.AddressToDelegation(env.Delegate)) {
+		t.Fatalf("code of %s = %x, want delegation to %s", authority, code, env.Delegate)
+	}
+}
+
+func address(key *ecdsa.PrivateKey) common.Address {
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func tokens(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+}
+
+func TestGetContracts(t *testing.T) {
+	env := New(t)
+	resp, err := http.Get(env.Server.URL + "/contracts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Token    common.Address `json:"token_contract"`
+		Delegate common.Address `json:"simple_delegate_contract"`
+		ChainID  uint64         `json:"chain_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	// Os endereços e a chain são os do backend, não os deploys da Holesky
+	if out.Token != env.Token || out.Delegate != env.Delegate || out.ChainID != 1337 {
+		t.Fatalf("contracts = %+v, want token %s, delegate %s, chain 1337", out, env.Token, env.Delegate)
+	}
+}
+
+func TestSponsorMint(t *testing.T) {
+	env := New(t)
+	authority, recipient := NewKey(t), address(NewKey(t))
+
+	post(t, env, "/sponsor-mint", eip7702.BasicSponsorRequest{
+		SignerPK:  KeyHex(authority),
+		Recipient: recipient.Hex(),
+		Amount:    "5",
+	})
+
+	requireDelegated(t, env, address(authority))
+	if got := env.TokenBalance(t, recipient); got.Cmp(tokens(5)) != 0 {
+		t.Fatalf("recipient balance = %s, want %s", got, tokens(5))
+	}
+}
+
+func TestSponsorTransfer(t *testing.T) {
+	env := New(t)
+	authority, recipient := NewKey(t), address(NewKey(t))
+
+	post(t, env, "/sponsor-mint", eip7702.BasicSponsorRequest{
+		SignerPK:  KeyHex(authority),
+		Recipient: address(authority).Hex(),
+		Amount:    "10",
+	})
+	// Segunda tx: a authority já está delegada e o nonce dela subiu com a autorização
+	post(t, env, "/sponsor-transfer", eip7702.BasicSponsorRequest{
+		SignerPK:  KeyHex(authority),
+		Recipient: recipient.Hex(),
+		Amount:    "4",
+	})
+
+	if got := env.TokenBalance(t, address(authority)); got.Cmp(tokens(6)) != 0 {
+		t.Fatalf("authority balance = %s, want %s", got, tokens(6))
+	}
+	if got := env.TokenBalance(t, recipient); got.Cmp(tokens(4)) != 0 {
+		t.Fatalf("recipient balance = %s, want %s", got, tokens(4))
+	}
+}
+
+func TestSponsorSendETH(t *testing.T) {
+	authority, recipient := NewKey(t), address(NewKey(t))
+	env := New(t, address(authority))
+	ctx := context.Background()
+	sponsorBefore, err := env.Client.BalanceAt(ctx, env.Sponsor.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := post(t, env, "/sponsor-eth", eip7702.BasicSponsorRequest{
+		SignerPK:  KeyHex(authority),
+		Recipient: recipient.Hex(),
+		Amount:    "0.5",
+	})
+
+	half := new(big.Int).Div(big.NewInt(params.Ether), big.NewInt(2))
+	if got, _ := env.Client.BalanceAt(ctx, recipient); got.Cmp(half) != 0 {
+		t.Fatalf("recipient balance = %s, want %s", got, half)
+	}
+	// O ETH sai da authority; o sponsor só paga o gas
+	if got, _ := env.Client.BalanceAt(ctx, address(authority)); got.Cmp(new(big.Int).Sub(DefaultBalance, half)) != 0 {
+		t.Fatalf("authority balance = %s", got)
+	}
+	gasCost := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	if got, _ := env.Client.BalanceAt(ctx, env.Sponsor.Address()); got.Cmp(new(big.Int).Sub(sponsorBefore, gasCost)) != 0 {
+		t.Fatalf("sponsor balance = %s, want %s - %s", got, sponsorBefore, gasCost)
+	}
+}
+
+func TestSponsorGenericExecute(t *testing.T) {
+	env := New(t)
+	authority, first, second := NewKey(t), address(NewKey(t)), address(NewKey(t))
+
+	// execute com duas chamadas ao Token.mint feitas pela authority
+	mint := func(to common.Address, amount *big.Int) map[string]interface{} {
+		data := append(crypto.Keccak256([]byte("mint(address,uint256)"))[:4], common.LeftPadBytes(to.Bytes(), 32)...)
+		data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+		return map[string]interface{}{"data": hexutil.Encode(data), "to": env.Token.Hex(), "value": "0"}
+	}
+	receipt := post(t, env, "/sponsor-generic", map[string]interface{}{
+		"signer_pk":          KeyHex(authority),
+		"contract_address":   env.Delegate.Hex(),
+		"function_signature": "execute((bytes,address,uint256)[])",
+		"parameters":         []interface{}{[]interface{}{mint(first, tokens(1)), mint(second, tokens(2))}},
+	})
+
+	requireDelegated(t, env, address(authority))
+	if got := env.TokenBalance(t, first); got.Cmp(tokens(1)) != 0 {
+		t.Fatalf("first balance = %s", got)
+	}
+	if got := env.TokenBalance(t, second); got.Cmp(tokens(2)) != 0 {
+		t.Fatalf("second balance = %s", got)
+	}
+
+	// Executed é emitido pela authority (código do delegate no contexto dela)
+	var executed int
+	for _, l := range receipt.Logs {
+		if l.Address == address(authority) {
+			executed++
+		}
+	}
+	if executed != 2 {
+		t.Fatalf("%d Executed logs from the authority, want 2", executed)
+	}
+}
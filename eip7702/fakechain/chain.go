@@ -0,0 +1,269 @@
+// Package fakechain é uma chain em memória que implementa eip7702.EthClient, para
+// testar o serviço e as rotas sem node. Guarda saldos, nonces e código das contas e
+// aplica SetCodeTx com a semântica da EIP-7702 (validação de cada autorização,
+// designator 0xef0100, nonce da authority). Não há EVM: a execução das calls é
+// delegada a um CallHandler opcional.
+package fakechain
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	// DefaultCallGas é o gas de execução cobrado quando a tx chama código
+	DefaultCallGas uint64 = 40_000
+	// genesisTime mantém os timestamps dos blocos determinísticos
+	genesisTime = 1_700_000_000
+	blockTime   = 12
+	blockGas    = 30_000_000
+)
+
+var (
+	// DefaultBaseFee é o base fee de todos os blocos (1 gwei)
+	DefaultBaseFee = big.NewInt(params.GWei)
+	// DefaultTip é a gorjeta sugerida e devolvida pelo eth_feeHistory (0.1 gwei)
+	DefaultTip = big.NewInt(params.GWei / 10)
+)
+
+// Call é uma execução repassada ao CallHandler. Code é o código efetivo de To
+// (já com overrides e autorizações aplicadas): use types.ParseDelegation para
+// saber para qual contrato a conta delegou.
+type Call struct {
+	From  common.Address
+	To    common.Address
+	Data  []byte
+	Value *big.Int
+	Code  []byte
+}
+
+// CallHandler executa uma call contra código; um erro (ex.: Revert) faz a call
+// reverter. Só é chamado quando To tem código.
+type CallHandler func(call Call) ([]byte, error)
+
+type account struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+}
+
+type minedTx struct {
+	tx      *types.Transaction
+	receipt *types.Receipt
+	auths   []AuthorizationResult
+}
+
+// Chain é o estado em memória. Os campos de configuração podem ser alterados antes
+// de usar a chain; depois, use os métodos (que são seguros para uso concorrente).
+type Chain struct {
+	BaseFee *big.Int
+	Tip     *big.Int
+	CallGas uint64
+	// AutoMine minera cada tx aceita em um bloco próprio; desligado, as txs ficam
+	// no pool (pending) até Mine
+	AutoMine    bool
+	CallHandler CallHandler
+
+	mu       sync.RWMutex
+	chainID  *big.Int
+	accounts map[common.Address]*account
+	headers  []*types.Header
+	pool     map[common.Hash]*pooledTx
+	mined    map[common.Hash]*minedTx
+}
+
+// New cria a chain com o bloco genesis e AutoMine ligado
+func New(chainID *big.Int) *Chain {
+	c := &Chain{
+		BaseFee:  new(big.Int).Set(DefaultBaseFee),
+		Tip:      new(big.Int).Set(DefaultTip),
+		CallGas:  DefaultCallGas,
+		AutoMine: true,
+		chainID:  new(big.Int).Set(chainID),
+		accounts: make(map[common.Address]*account),
+		pool:     make(map[common.Hash]*pooledTx),
+		mined:    make(map[common.Hash]*minedTx),
+	}
+	c.headers = append(c.headers, c.newHeader(0, common.Hash{}))
+	return c
+}
+
+// ===== Setup do estado =====
+
+// Fund soma wei ao saldo da conta
+func (c *Chain) Fund(addr common.Address, wei *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	acc := c.account(addr)
+	acc.balance.Add(acc.balance, wei)
+}
+
+// SetCode instala código na conta (ex.: um contrato ou um designator)
+func (c *Chain) SetCode(addr common.Address, code []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.account(addr).code = common.CopyBytes(code)
+}
+
+// SetNonce define o nonce da conta
+func (c *Chain) SetNonce(addr common.Address, nonce uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.account(addr).nonce = nonce
+}
+
+// account devolve a conta, criando-a vazia (chamar com o lock de escrita)
+func (c *Chain) account(addr common.Address) *account {
+	acc, ok := c.accounts[addr]
+	if !ok {
+		acc = &account{balance: new(big.Int)}
+		c.accounts[addr] = acc
+	}
+	return acc
+}
+
+func (c *Chain) newHeader(number uint64, parent common.Hash) *types.Header {
+	return &types.Header{
+		ParentHash: parent,
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   blockGas,
+		Time:       genesisTime + number*blockTime,
+		BaseFee:    new(big.Int).Set(c.BaseFee),
+		Difficulty: new(big.Int),
+	}
+}
+
+// ===== Leituras (EthClient) =====
+
+func (c *Chain) ChainID(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.chainID), nil
+}
+
+func (c *Chain) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if acc, ok := c.accounts[account]; ok {
+		return new(big.Int).Set(acc.balance), nil
+	}
+	return new(big.Int), nil
+}
+
+func (c *Chain) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.nonce(from), nil
+}
+
+// PendingNonceAt conta também as txs do pool
+func (c *Chain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pendingNonce(account), nil
+}
+
+func (c *Chain) CodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if acc, ok := c.accounts[account]; ok {
+		return common.CopyBytes(acc.code), nil
+	}
+	return nil, nil
+}
+
+func (c *Chain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return uint64(len(c.headers) - 1), nil
+}
+
+// HeaderByNumber aceita nil (último bloco) ou um número já minerado
+func (c *Chain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if number == nil {
+		return types.CopyHeader(c.headers[len(c.headers)-1]), nil
+	}
+	if !number.IsUint64() || number.Uint64() >= uint64(len(c.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return types.CopyHeader(c.headers[number.Uint64()]), nil
+}
+
+func (c *Chain) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(c.Tip), nil
+}
+
+// FeeHistory devolve o base fee dos blocos e Tip em todos os percentis
+func (c *Chain) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	last := uint64(len(c.headers) - 1)
+	if lastBlock != nil && lastBlock.IsUint64() && lastBlock.Uint64() < last {
+		last = lastBlock.Uint64()
+	}
+	blockCount = min(blockCount, last+1)
+	oldest := last + 1 - blockCount
+
+	history := &ethereum.FeeHistory{OldestBlock: new(big.Int).SetUint64(oldest)}
+	for n := oldest; n <= last; n++ {
+		reward := make([]*big.Int, len(rewardPercentiles))
+		for i := range reward {
+			reward[i] = new(big.Int).Set(c.Tip)
+		}
+		history.Reward = append(history.Reward, reward)
+		history.BaseFee = append(history.BaseFee, new(big.Int).Set(c.headers[n].BaseFee))
+		history.GasUsedRatio = append(history.GasUsedRatio, float64(c.headers[n].GasUsed)/blockGas)
+	}
+	// O próximo bloco mantém o base fee (a fake não ajusta pelo uso)
+	history.BaseFee = append(history.BaseFee, new(big.Int).Set(c.BaseFee))
+	return history, nil
+}
+
+func (c *Chain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, ok := c.mined[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	receipt := *m.receipt
+	return &receipt, nil
+}
+
+// TransactionByHash procura no pool (pending) e nos blocos
+func (c *Chain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if p, ok := c.pool[hash]; ok {
+		return p.tx, true, nil
+	}
+	if m, ok := c.mined[hash]; ok {
+		return m.tx, false, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+// nonce e pendingNonce: chamar com o lock (leitura basta)
+func (c *Chain) nonce(addr common.Address) uint64 {
+	if acc, ok := c.accounts[addr]; ok {
+		return acc.nonce
+	}
+	return 0
+}
+
+func (c *Chain) pendingNonce(addr common.Address) uint64 {
+	next := c.nonce(addr)
+	for {
+		if c.pooled(addr, next) == nil {
+			return next
+		}
+		next++
+	}
+}
@@ -0,0 +1,335 @@
+package fakechain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	testChainID  = big.NewInt(1337)
+	testDelegate = common.HexToAddress("0x00000000000000000000000000000000000d1e9a")
+)
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func signAuth(t *testing.T, key *ecdsa.PrivateKey, chainID uint64, delegate common.Address, nonce uint64) types.SetCodeAuthorization {
+	t.Helper()
+	auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(chainID),
+		Address: delegate,
+		Nonce:   nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+// setCodeTx assina uma SetCodeTx do sponsor para to com fee cap feeCap (gorjeta = DefaultTip)
+func setCodeTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to common.Address, feeCap *big.Int, auths ...types.SetCodeAuthorization) *types.Transaction {
+	t.Helper()
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(testChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(testChainID),
+		Nonce:     nonce,
+		GasTipCap: uint256.MustFromBig(DefaultTip),
+		GasFeeCap: uint256.MustFromBig(feeCap),
+		Gas:       200_000,
+		To:        to,
+		Value:     uint256.NewInt(0),
+		AuthList:  auths,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func gwei(n int64) *big.Int { return big.NewInt(n * params.GWei) }
+
+func TestSetCodeTxInstallsDesignator(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	sponsorKey, sponsor := newKey(t)
+	authKey, authority := newKey(t)
+	chain.Fund(sponsor, big.NewInt(params.Ether))
+
+	tx := setCodeTx(t, sponsorKey, 0, authority, gwei(2), signAuth(t, authKey, testChainID.Uint64(), testDelegate, 0))
+	if err := chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	receipt, err := chain.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("status = %d, want success", receipt.Status)
+	}
+
+	code, _ := chain.CodeAt(ctx, authority)
+	if !bytes.Equal(code, types.AddressToDelegation(testDelegate)) {
+		t.Fatalf("code = %x, want designator for %s", code, testDelegate)
+	}
+	if nonce, _ := chain.NonceAt(ctx, authority); nonce != 1 {
+		t.Fatalf("authority nonce = %d, want 1", nonce)
+	}
+	if nonce, _ := chain.NonceAt(ctx, sponsor); nonce != 1 {
+		t.Fatalf("sponsor nonce = %d, want 1", nonce)
+	}
+
+	results, err := chain.Authorizations(tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Applied || results[0].Authority != authority {
+		t.Fatalf("authorization results = %+v", results)
+	}
+
+	balance, _ := chain.BalanceAt(ctx, sponsor)
+	if want := new(big.Int).Sub(big.NewInt(params.Ether), new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))); balance.Cmp(want) != 0 {
+		t.Fatalf("sponsor balance = %s, want %s", balance, want)
+	}
+}
+
+func TestRevocationClearsCode(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	sponsorKey, sponsor := newKey(t)
+	authKey, authority := newKey(t)
+	chain.Fund(sponsor, big.NewInt(params.Ether))
+	chain.SetCode(authority, types.AddressToDelegation(testDelegate))
+	chain.SetNonce(authority, 3)
+
+	tx := setCodeTx(t, sponsorKey, 0, authority, gwei(2), signAuth(t, authKey, 0, common.Address{}, 3))
+	if err := chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if code, _ := chain.CodeAt(ctx, authority); len(code) != 0 {
+		t.Fatalf("code = %x, want empty", code)
+	}
+	if nonce, _ := chain.NonceAt(ctx, authority); nonce != 4 {
+		t.Fatalf("authority nonce = %d, want 4", nonce)
+	}
+}
+
+func TestInvalidAuthorizationsAreSkipped(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	sponsorKey, sponsor := newKey(t)
+	wrongChainKey, wrongChain := newKey(t)
+	wrongNonceKey, wrongNonce := newKey(t)
+	contractKey, contract := newKey(t)
+	chain.Fund(sponsor, big.NewInt(params.Ether))
+	chain.SetCode(contract, []byte{0x60, 0x00})
+
+	tx := setCodeTx(t, sponsorKey, 0, sponsor, gwei(2),
+		signAuth(t, wrongChainKey, 1, testDelegate, 0),
+		signAuth(t, wrongNonceKey, testChainID.Uint64(), testDelegate, 5),
+		signAuth(t, contractKey, testChainID.Uint64(), testDelegate, 0),
+	)
+	if err := chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	// Tuplas inválidas não revertem a tx
+	receipt, err := chain.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("receipt = %+v, err %v", receipt, err)
+	}
+
+	results, err := chain.Authorizations(tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		authority common.Address
+		err       error
+	}{
+		{common.Address{}, core.ErrAuthorizationWrongChainID}, // chain id é conferido antes de recuperar a authority
+		{wrongNonce, core.ErrAuthorizationNonceMismatch},
+		{contract, core.ErrAuthorizationDestinationHasCode},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Applied || r.Authority != w.authority || r.Error != w.err.Error() {
+			t.Errorf("result %d = %+v, want error %q", i, r, w.err)
+		}
+	}
+
+	if code, _ := chain.CodeAt(ctx, wrongChain); len(code) != 0 {
+		t.Errorf("wrong-chain authority got code %x", code)
+	}
+	if nonce, _ := chain.NonceAt(ctx, wrongNonce); nonce != 0 {
+		t.Errorf("wrong-nonce authority nonce = %d, want 0", nonce)
+	}
+	if code, _ := chain.CodeAt(ctx, contract); !bytes.Equal(code, []byte{0x60, 0x00}) {
+		t.Errorf("contract code replaced: %x", code)
+	}
+}
+
+func TestSendTransactionValidation(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	sponsorKey, sponsor := newKey(t)
+	authKey, authority := newKey(t)
+	chain.Fund(sponsor, big.NewInt(params.Ether))
+	auth := signAuth(t, authKey, testChainID.Uint64(), testDelegate, 0)
+
+	cases := []struct {
+		name string
+		tx   *types.Transaction
+		want string
+	}{
+		{"empty auth list", setCodeTx(t, sponsorKey, 0, authority, gwei(2)), "EIP-7702 transaction with empty auth list"},
+		{"nonce too high", setCodeTx(t, sponsorKey, 1, authority, gwei(2), auth), "nonce too high"},
+		{"fee cap below base fee", setCodeTx(t, sponsorKey, 0, authority, big.NewInt(params.GWei/2), auth), "max fee per gas less than block base fee"},
+		{"insufficient funds", setCodeTx(t, sponsorKey, 0, authority, big.NewInt(params.Ether), auth), "insufficient funds"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := chain.SendTransaction(ctx, tc.tx)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want %q", err, tc.want)
+			}
+			// Recusas imitam o erro JSON-RPC do node
+			var rpcErr interface{ ErrorCode() int }
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("err %T is not a JSON-RPC error", err)
+			}
+		})
+	}
+}
+
+func TestReplacementRequiresFeeBump(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	chain.AutoMine = false
+	sponsorKey, sponsor := newKey(t)
+	authKey, authority := newKey(t)
+	chain.Fund(sponsor, big.NewInt(params.Ether))
+	auth := signAuth(t, authKey, testChainID.Uint64(), testDelegate, 0)
+
+	orig := setCodeTx(t, sponsorKey, 0, authority, gwei(10), auth)
+	if err := chain.SendTransaction(ctx, orig); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if err := chain.SendTransaction(ctx, orig); err == nil || !strings.Contains(err.Error(), "already known") {
+		t.Fatalf("resend err = %v, want already known", err)
+	}
+	if pending, _ := chain.PendingNonceAt(ctx, sponsor); pending != 1 {
+		t.Fatalf("pending nonce = %d, want 1", pending)
+	}
+
+	// Mesma gorjeta: a substituta precisa subir gorjeta e fee cap em 10%
+	underpriced := setCodeTx(t, sponsorKey, 0, authority, gwei(20), auth)
+	if err := chain.SendTransaction(ctx, underpriced); err == nil || !strings.Contains(err.Error(), "replacement transaction underpriced") {
+		t.Fatalf("underpriced err = %v", err)
+	}
+
+	replacement, err := types.SignNewTx(sponsorKey, types.LatestSignerForChainID(testChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(testChainID),
+		GasTipCap: uint256.MustFromBig(bumped(DefaultTip)),
+		GasFeeCap: uint256.MustFromBig(bumped(gwei(10))),
+		Gas:       200_000,
+		To:        authority,
+		Value:     uint256.NewInt(0),
+		AuthList:  []types.SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.SendTransaction(ctx, replacement); err != nil {
+		t.Fatalf("replacement: %v", err)
+	}
+	if _, _, err := chain.TransactionByHash(ctx, orig.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("original still known: %v", err)
+	}
+
+	chain.Mine()
+	if _, err := chain.TransactionReceipt(ctx, replacement.Hash()); err != nil {
+		t.Fatalf("replacement not mined: %v", err)
+	}
+	if _, err := chain.TransactionReceipt(ctx, orig.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("original mined: %v", err)
+	}
+}
+
+func TestEstimateGasAppliesAuthorizationList(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	authKey, authority := newKey(t)
+	_, sponsor := newKey(t)
+
+	var called []Call
+	chain.CallHandler = func(call Call) ([]byte, error) {
+		called = append(called, call)
+		return nil, nil
+	}
+
+	msg := ethereum.CallMsg{
+		From:              sponsor,
+		To:                &authority,
+		Data:              []byte{0x01, 0x02, 0x03, 0x04},
+		AuthorizationList: []types.SetCodeAuthorization{signAuth(t, authKey, testChainID.Uint64(), testDelegate, 0)},
+	}
+	withAuth, err := chain.EstimateGas(ctx, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.AuthorizationList = nil
+	without, err := chain.EstimateGas(ctx, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Com a AuthList o destino tem código: execução cobrada e CallHandler chamado
+	if withAuth <= without || withAuth-without < DefaultCallGas {
+		t.Fatalf("estimate with auth = %d, without = %d", withAuth, without)
+	}
+	if len(called) != 1 {
+		t.Fatalf("call handler invoked %d times, want 1", len(called))
+	}
+	if delegate, ok := types.ParseDelegation(called[0].Code); !ok || delegate != testDelegate {
+		t.Fatalf("call code = %x", called[0].Code)
+	}
+	// Estimativa não altera o estado
+	if code, _ := chain.CodeAt(ctx, authority); len(code) != 0 {
+		t.Fatalf("estimate installed code %x", code)
+	}
+}
+
+func TestCallHandlerRevert(t *testing.T) {
+	ctx := context.Background()
+	chain := New(testChainID)
+	_, authority := newKey(t)
+	chain.SetCode(authority, types.AddressToDelegation(testDelegate))
+	chain.CallHandler = func(call Call) ([]byte, error) {
+		return nil, Revert([]byte{0xde, 0xad, 0xbe, 0xef})
+	}
+
+	_, err := chain.CallContract(ctx, ethereum.CallMsg{To: &authority}, nil)
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) || dataErr.ErrorData() != "0xdeadbeef" {
+		t.Fatalf("err = %v, want revert with data", err)
+	}
+}
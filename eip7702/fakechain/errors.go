@@ -0,0 +1,33 @@
+package fakechain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// rpcError imita o erro JSON-RPC do geth (rpc.Error / rpc.DataError), para que
+// o serviço trate as recusas da fake como respostas do node e não falhas de rede
+type rpcError struct {
+	code int
+	msg  string
+	data interface{}
+}
+
+func (e *rpcError) Error() string          { return e.msg }
+func (e *rpcError) ErrorCode() int         { return e.code }
+func (e *rpcError) ErrorData() interface{} { return e.data }
+
+// rejected é uma recusa do txpool/validação (código genérico do geth)
+func rejected(format string, args ...interface{}) error {
+	return &rpcError{code: -32000, msg: fmt.Sprintf(format, args...)}
+}
+
+// Revert devolve, dentro de um CallHandler, o erro de "execution reverted" com os
+// dados do revert, como o geth responde em eth_call/eth_estimateGas
+func Revert(data []byte) error {
+	if len(data) == 0 {
+		return &rpcError{code: -32000, msg: "execution reverted"}
+	}
+	return &rpcError{code: 3, msg: "execution reverted", data: hexutil.Encode(data)}
+}
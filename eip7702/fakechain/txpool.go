@@ -0,0 +1,481 @@
+package fakechain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// replacementBumpPercent é o aumento mínimo (geth: txpool.pricebump) para substituir
+// uma tx pendente no mesmo nonce
+const replacementBumpPercent = 10
+
+type pooledTx struct {
+	tx   *types.Transaction
+	from common.Address
+}
+
+// AuthorizationResult é o resultado de uma tupla da AuthList ao minerar a SetCodeTx.
+// Tuplas inválidas são ignoradas sem reverter a tx, como no protocolo.
+type AuthorizationResult struct {
+	Authority common.Address `json:"authority"`
+	Address   common.Address `json:"address"`
+	Nonce     uint64         `json:"nonce"`
+	Applied   bool           `json:"applied"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// SendTransaction valida a tx como o txpool do geth (mesmas mensagens de erro) e a
+// coloca no pool; com AutoMine ela é minerada na hora
+func (c *Chain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	from, err := types.Sender(types.LatestSignerForChainID(c.chainID), tx)
+	if err != nil {
+		return rejected("invalid sender: %v", err)
+	}
+	if _, ok := c.pool[tx.Hash()]; ok {
+		return rejected("already known")
+	}
+	if _, ok := c.mined[tx.Hash()]; ok {
+		return rejected("already known")
+	}
+	if tx.Type() == types.SetCodeTxType && len(tx.SetCodeAuthorizations()) == 0 {
+		return rejected("EIP-7702 transaction with empty auth list")
+	}
+
+	nonce, pending := c.nonce(from), c.pendingNonce(from)
+	if tx.Nonce() < nonce {
+		return rejected("nonce too low: address %v, tx: %d state: %d", from.Hex(), tx.Nonce(), nonce)
+	}
+	if tx.Nonce() > pending {
+		return rejected("nonce too high: address %v, tx: %d state: %d", from.Hex(), tx.Nonce(), pending)
+	}
+
+	intrinsic, floor, err := intrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil)
+	if err != nil {
+		return rejected("%v", err)
+	}
+	if tx.Gas() < intrinsic {
+		return rejected("intrinsic gas too low: gas %d, minimum needed %d", tx.Gas(), intrinsic)
+	}
+	if tx.Gas() < floor {
+		return rejected("insufficient gas for floor data gas cost: gas %d, minimum needed %d", tx.Gas(), floor)
+	}
+	if tx.GasTipCap().Cmp(tx.GasFeeCap()) > 0 {
+		return rejected("max priority fee per gas higher than max fee per gas: address %v, maxPriorityFeePerGas: %s, maxFeePerGas: %s",
+			from.Hex(), tx.GasTipCap(), tx.GasFeeCap())
+	}
+	if tx.GasFeeCap().Cmp(c.BaseFee) < 0 {
+		return rejected("max fee per gas less than block base fee: address %v, maxFeePerGas: %s, baseFee: %s",
+			from.Hex(), tx.GasFeeCap(), c.BaseFee)
+	}
+	if have, want := c.account(from).balance, tx.Cost(); have.Cmp(want) < 0 {
+		return rejected("insufficient funds for gas * price + value: address %v have %v want %v", from.Hex(), have, want)
+	}
+
+	if old := c.pooled(from, tx.Nonce()); old != nil {
+		if tx.GasTipCap().Cmp(bumped(old.tx.GasTipCap())) < 0 || tx.GasFeeCap().Cmp(bumped(old.tx.GasFeeCap())) < 0 {
+			return rejected("replacement transaction underpriced")
+		}
+		delete(c.pool, old.tx.Hash())
+	}
+	c.pool[tx.Hash()] = &pooledTx{tx: tx, from: from}
+
+	if c.AutoMine {
+		c.mine()
+	}
+	return nil
+}
+
+// Mine minera as txs executáveis do pool em um novo bloco (mesmo sem txs)
+func (c *Chain) Mine() *types.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return types.CopyHeader(c.mine())
+}
+
+// Drop tira a tx do pool, como um node que a descartou; false se não estava pendente
+func (c *Chain) Drop(hash common.Hash) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pool[hash]; !ok {
+		return false
+	}
+	delete(c.pool, hash)
+	return true
+}
+
+// Authorizations devolve o resultado de cada tupla da AuthList de uma tx minerada
+func (c *Chain) Authorizations(hash common.Hash) ([]AuthorizationResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, ok := c.mined[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return append([]AuthorizationResult(nil), m.auths...), nil
+}
+
+// pooled devolve a tx pendente do remetente naquele nonce (chamar com o lock)
+func (c *Chain) pooled(from common.Address, nonce uint64) *pooledTx {
+	for _, p := range c.pool {
+		if p.from == from && p.tx.Nonce() == nonce {
+			return p
+		}
+	}
+	return nil
+}
+
+// mine aplica, em ordem de nonce, as txs do pool que ficaram executáveis. Txs cujo
+// nonce já foi usado (ex.: por uma autorização) são descartadas. Chamar com o lock.
+func (c *Chain) mine() *types.Header {
+	parent := c.headers[len(c.headers)-1]
+	header := c.newHeader(parent.Number.Uint64()+1, parent.Hash())
+
+	var txs []*minedTx
+	for {
+		next := c.executable()
+		if next == nil {
+			break
+		}
+		delete(c.pool, next.tx.Hash())
+		if m := c.apply(header, next, uint(len(txs))); m != nil {
+			txs = append(txs, m)
+		}
+	}
+	for hash, p := range c.pool {
+		if p.tx.Nonce() < c.nonce(p.from) {
+			delete(c.pool, hash)
+		}
+	}
+
+	blockHash := header.Hash()
+	for _, m := range txs {
+		m.receipt.BlockHash = blockHash
+		for _, l := range m.receipt.Logs {
+			l.BlockHash = blockHash
+		}
+		c.mined[m.tx.Hash()] = m
+	}
+	c.headers = append(c.headers, header)
+	return header
+}
+
+// executable escolhe a próxima tx com o nonce atual do remetente (ordem determinística)
+func (c *Chain) executable() *pooledTx {
+	var ready []*pooledTx
+	for _, p := range c.pool {
+		if p.tx.Nonce() == c.nonce(p.from) {
+			ready = append(ready, p)
+		}
+	}
+	if len(ready) == 0 {
+		return nil
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return bytes.Compare(ready[i].from[:], ready[j].from[:]) < 0
+	})
+	return ready[0]
+}
+
+// apply executa a tx sobre o estado. Sem saldo para o gas na hora de minerar, a tx
+// é descartada (nil), como faria o minerador.
+func (c *Chain) apply(header *types.Header, p *pooledTx, index uint) *minedTx {
+	tx, from := p.tx, p.from
+	sender := c.account(from)
+
+	price := effectiveGasPrice(tx, header.BaseFee)
+	maxCost := new(big.Int).Mul(price, new(big.Int).SetUint64(tx.Gas()))
+	if sender.balance.Cmp(maxCost) < 0 {
+		return nil
+	}
+
+	// Como no geth: o nonce do remetente sobe antes das autorizações
+	sender.nonce++
+
+	state := c.overlay()
+	auths, refunds := state.applyAuthorizations(tx.SetCodeAuthorizations(), c.chainID)
+	state.commit()
+
+	intrinsic, floor, _ := intrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil)
+	gasUsed := intrinsic
+	status := types.ReceiptStatusSuccessful
+
+	var to common.Address
+	if tx.To() != nil {
+		to = *tx.To()
+	}
+	code := c.account(to).code
+	value := tx.Value()
+	switch {
+	case len(code) > 0 && intrinsic+c.CallGas > tx.Gas():
+		// Out of gas: consome tudo
+		gasUsed, status = tx.Gas(), types.ReceiptStatusFailed
+	case len(code) > 0:
+		gasUsed += c.CallGas
+		if c.CallHandler != nil {
+			if _, err := c.CallHandler(Call{From: from, To: to, Data: tx.Data(), Value: value, Code: code}); err != nil {
+				status = types.ReceiptStatusFailed
+			}
+		}
+	}
+	if status == types.ReceiptStatusSuccessful && value.Sign() > 0 {
+		if sender.balance.Cmp(new(big.Int).Add(maxCost, value)) < 0 {
+			status = types.ReceiptStatusFailed
+		} else {
+			sender.balance.Sub(sender.balance, value)
+			c.account(to).balance.Add(c.account(to).balance, value)
+		}
+	}
+
+	// Reembolso das contas que já existiam (EIP-7702), limitado a 1/5 do gas (EIP-3529)
+	gasUsed -= min(refunds*(params.CallNewAccountGas-params.TxAuthTupleGas), gasUsed/params.RefundQuotientEIP3529)
+	gasUsed = max(gasUsed, floor)
+	sender.balance.Sub(sender.balance, new(big.Int).Mul(price, new(big.Int).SetUint64(gasUsed)))
+
+	header.GasUsed += gasUsed
+	receipt := &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: header.GasUsed,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Hash(),
+		GasUsed:           gasUsed,
+		EffectiveGasPrice: price,
+		BlockNumber:       new(big.Int).Set(header.Number),
+		TransactionIndex:  index,
+	}
+	return &minedTx{tx: tx, receipt: receipt, auths: auths}
+}
+
+// ===== Simulação (eth_estimateGas, eth_call, eth_createAccessList) =====
+
+// EstimateGas devolve o intrínseco mais CallGas quando o destino (com a AuthList da
+// msg aplicada) tem código; revert do CallHandler volta como erro
+func (c *Chain) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	intrinsic, floor, err := intrinsicGas(msg.Data, msg.AccessList, msg.AuthorizationList, msg.To == nil)
+	if err != nil {
+		return 0, rejected("%v", err)
+	}
+	if msg.Value != nil && c.balance(msg.From).Cmp(msg.Value) < 0 {
+		return 0, rejected("insufficient funds for transfer")
+	}
+
+	_, ran, err := c.call(msg, nil)
+	if err != nil {
+		return 0, err
+	}
+	gas := intrinsic
+	if ran {
+		gas += c.CallGas
+	}
+	return max(gas, floor), nil
+}
+
+// CallContract executa a call no CallHandler; codeOverrides substitui o código das
+// contas (como o state override do eth_call)
+func (c *Chain) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ret, _, err := c.call(msg, codeOverrides)
+	return ret, err
+}
+
+// CreateAccessList não tem slots para aquecer: devolve lista vazia e a estimativa
+func (c *Chain) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	gas, err := c.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return types.AccessList{}, gas, nil
+}
+
+// call aplica a AuthList da msg numa cópia do estado e chama o CallHandler se o
+// destino tiver código; ran indica se houve execução. Chamar com o lock.
+func (c *Chain) call(msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) (ret []byte, ran bool, err error) {
+	if msg.To == nil {
+		return nil, false, nil
+	}
+
+	state := c.overlay()
+	state.setNonce(msg.From, state.nonce(msg.From)+1)
+	state.applyAuthorizations(msg.AuthorizationList, c.chainID)
+
+	code, ok := codeOverrides[*msg.To]
+	if !ok {
+		code = state.code(*msg.To)
+	}
+	if len(code) == 0 {
+		return nil, false, nil
+	}
+	if c.CallHandler == nil {
+		return nil, true, nil
+	}
+
+	value := msg.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	ret, err = c.CallHandler(Call{From: msg.From, To: *msg.To, Data: msg.Data, Value: value, Code: code})
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			err = &rpcError{code: -32000, msg: "execution reverted: " + err.Error()}
+		}
+		return nil, true, err
+	}
+	return ret, true, nil
+}
+
+// ===== Autorizações =====
+
+// overlay acumula nonces e código sem tocar no estado até commit
+type overlay struct {
+	c      *Chain
+	nonces map[common.Address]uint64
+	codes  map[common.Address][]byte
+}
+
+func (c *Chain) overlay() *overlay {
+	return &overlay{c: c, nonces: make(map[common.Address]uint64), codes: make(map[common.Address][]byte)}
+}
+
+func (o *overlay) nonce(addr common.Address) uint64 {
+	if n, ok := o.nonces[addr]; ok {
+		return n
+	}
+	return o.c.nonce(addr)
+}
+
+func (o *overlay) code(addr common.Address) []byte {
+	if code, ok := o.codes[addr]; ok {
+		return code
+	}
+	if acc, ok := o.c.accounts[addr]; ok {
+		return acc.code
+	}
+	return nil
+}
+
+func (o *overlay) setNonce(addr common.Address, n uint64)   { o.nonces[addr] = n }
+func (o *overlay) setCode(addr common.Address, code []byte) { o.codes[addr] = code }
+
+// exists segue a regra de conta existente do geth (nonce, saldo ou código)
+func (o *overlay) exists(addr common.Address) bool {
+	acc, ok := o.c.accounts[addr]
+	return o.nonce(addr) > 0 || len(o.code(addr)) > 0 || (ok && acc.balance.Sign() > 0)
+}
+
+// commit grava o overlay no estado (chamar com o lock de escrita)
+func (o *overlay) commit() {
+	for addr, n := range o.nonces {
+		o.c.account(addr).nonce = n
+	}
+	for addr, code := range o.codes {
+		o.c.account(addr).code = code
+	}
+}
+
+// applyAuthorizations valida e aplica cada tupla como core.stateTransition:
+// chain id 0 ou da chain, assinatura válida, authority sem código (ou já delegada)
+// e nonce igual ao da conta. refunds conta as authorities que já existiam.
+func (o *overlay) applyAuthorizations(auths []types.SetCodeAuthorization, chainID *big.Int) (results []AuthorizationResult, refunds uint64) {
+	for _, auth := range auths {
+		result := AuthorizationResult{Address: auth.Address, Nonce: auth.Nonce}
+		authority, err := o.validateAuthorization(&auth, chainID)
+		result.Authority = authority
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		if o.exists(authority) {
+			refunds++
+		}
+		o.setNonce(authority, auth.Nonce+1)
+		if auth.Address == (common.Address{}) {
+			// Delegar para o endereço zero limpa o código
+			o.setCode(authority, nil)
+		} else {
+			o.setCode(authority, types.AddressToDelegation(auth.Address))
+		}
+		result.Applied = true
+		results = append(results, result)
+	}
+	return results, refunds
+}
+
+func (o *overlay) validateAuthorization(auth *types.SetCodeAuthorization, chainID *big.Int) (common.Address, error) {
+	if !auth.ChainID.IsZero() && auth.ChainID.CmpBig(chainID) != 0 {
+		return common.Address{}, core.ErrAuthorizationWrongChainID
+	}
+	if auth.Nonce+1 < auth.Nonce {
+		return common.Address{}, core.ErrAuthorizationNonceOverflow
+	}
+	authority, err := auth.Authority()
+	if err != nil {
+		return authority, core.ErrAuthorizationInvalidSignature
+	}
+	if code := o.code(authority); len(code) > 0 {
+		if _, ok := types.ParseDelegation(code); !ok {
+			return authority, core.ErrAuthorizationDestinationHasCode
+		}
+	}
+	if o.nonce(authority) != auth.Nonce {
+		return authority, core.ErrAuthorizationNonceMismatch
+	}
+	return authority, nil
+}
+
+// ===== Helpers =====
+
+// balance devolve o saldo sem criar a conta (leitura)
+func (c *Chain) balance(addr common.Address) *big.Int {
+	if acc, ok := c.accounts[addr]; ok {
+		return acc.balance
+	}
+	return new(big.Int)
+}
+
+// intrinsicGas devolve o custo intrínseco (Prague) e o piso de dados da EIP-7623
+func intrinsicGas(data []byte, accessList types.AccessList, authList []types.SetCodeAuthorization, creation bool) (uint64, uint64, error) {
+	gas, err := core.IntrinsicGas(data, accessList, authList, creation, true, true, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	floor, err := core.FloorDataGas(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return gas, floor, nil
+}
+
+// effectiveGasPrice é min(fee cap, base fee + gorjeta)
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
+
+// bumped devolve ceil(v * (100 + replacementBumpPercent) / 100)
+func bumped(v *big.Int) *big.Int {
+	out := new(big.Int).Mul(v, big.NewInt(100+replacementBumpPercent))
+	out.Add(out, big.NewInt(99))
+	return out.Div(out, big.NewInt(100))
+}
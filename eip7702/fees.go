@@ -0,0 +1,118 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// DefaultBaseFeeMultiplier cobre a alta do base fee por alguns blocos (+12,5% por bloco cheio)
+const DefaultBaseFeeMultiplier = 2.0
+
+// Parâmetros do eth_feeHistory usados para sugerir a gorjeta
+const (
+	feeHistoryBlocks     uint64  = 10
+	feeHistoryPercentile float64 = 50
+)
+
+// defaultGasTipCap é usado quando o node não sugere gorjeta
+var defaultGasTipCap = big.NewInt(2_000_000_000) // 2 Gwei
+
+// ErrMaxFeeTooLow indica que o teto do request não cobre nem o base fee atual
+var ErrMaxFeeTooLow = errors.New("max fee per gas below current base fee")
+
+// ErrGasPriceTooHigh indica que o fee cap calculado passa do teto estrito do request
+var ErrGasPriceTooHigh = errors.New("computed fee cap above max gas price")
+
+// SponsorOptions são os ajustes por request de uma tx patrocinada
+type SponsorOptions struct {
+	// MaxFeePerGas limita o GasFeeCap (wei); nil = sem limite além do calculado
+	MaxFeePerGas *big.Int
+	// StrictMaxFee recusa a tx (ErrGasPriceTooHigh) em vez de limitar o fee cap ao MaxFeePerGas
+	StrictMaxFee bool
+	// Simulate roda eth_call antes de assinar e aborta com SimulationError se reverter
+	Simulate bool
+	// AccessList anexa a access list do eth_createAccessList quando ela reduz o gas
+	AccessList bool
+}
+
+// Fees são os campos EIP-1559 calculados para a tx
+type Fees struct {
+	BaseFee   *big.Int `json:"base_fee"`
+	GasTipCap *big.Int `json:"max_priority_fee_per_gas"`
+	GasFeeCap *big.Int `json:"max_fee_per_gas"`
+}
+
+// suggestFees calcula GasFeeCap = base fee * multiplicador + gorjeta, limitado por maxFee
+func (d *DelegationService) suggestFees(ctx context.Context, maxFee *big.Int) (*Fees, error) {
+	header, err := d.RPC.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("chain does not report a base fee (EIP-1559 required)")
+	}
+
+	tip := d.suggestGasTipCap(ctx)
+
+	multiplier := d.BaseFeeMultiplier
+	if multiplier < 1 {
+		multiplier = DefaultBaseFeeMultiplier
+	}
+	// Multiplicador em milésimos para manter a conta em inteiros
+	feeCap := new(big.Int).Mul(header.BaseFee, big.NewInt(int64(multiplier*1000)))
+	feeCap.Div(feeCap, big.NewInt(1000))
+	feeCap.Add(feeCap, tip)
+
+	if maxFee != nil && feeCap.Cmp(maxFee) > 0 {
+		if maxFee.Cmp(header.BaseFee) < 0 {
+			return nil, fmt.Errorf("%w: max %s, base fee %s", ErrMaxFeeTooLow, maxFee, header.BaseFee)
+		}
+		feeCap = new(big.Int).Set(maxFee)
+		// A gorjeta nunca pode passar do teto
+		if tip.Cmp(feeCap) > 0 {
+			tip = new(big.Int).Set(feeCap)
+		}
+	}
+
+	return &Fees{BaseFee: header.BaseFee, GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+// feesFor aplica as opções do request: teto limita o fee cap ou, se estrito, recusa a tx
+func (d *DelegationService) feesFor(ctx context.Context, opts SponsorOptions) (*Fees, error) {
+	if !opts.StrictMaxFee || opts.MaxFeePerGas == nil {
+		return d.suggestFees(ctx, opts.MaxFeePerGas)
+	}
+	fees, err := d.suggestFees(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if fees.GasFeeCap.Cmp(opts.MaxFeePerGas) > 0 {
+		return nil, fmt.Errorf("%w: fee cap %s, max %s", ErrGasPriceTooHigh, fees.GasFeeCap, opts.MaxFeePerGas)
+	}
+	return fees, nil
+}
+
+// suggestGasTipCap usa a mediana das gorjetas recentes (eth_feeHistory),
+// com eth_maxPriorityFeePerGas e 2 Gwei como fallbacks
+func (d *DelegationService) suggestGasTipCap(ctx context.Context) *big.Int {
+	if history, err := d.RPC.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryPercentile}); err == nil {
+		var rewards []*big.Int
+		for _, block := range history.Reward {
+			if len(block) > 0 && block[0] != nil && block[0].Sign() > 0 {
+				rewards = append(rewards, block[0])
+			}
+		}
+		if len(rewards) > 0 {
+			sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+			return new(big.Int).Set(rewards[len(rewards)/2])
+		}
+	}
+
+	if tip, err := d.RPC.SuggestGasTipCap(ctx); err == nil {
+		return tip
+	}
+	return new(big.Int).Set(defaultGasTipCap)
+}
@@ -0,0 +1,135 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// feeRPC responde o base fee, o histórico de gorjetas e a sugestão do node
+type feeRPC struct {
+	EthClient
+	baseFee *big.Int
+	rewards []*big.Int // percentil 50 de cada bloco; nil = eth_feeHistory falha
+	tip     *big.Int   // nil = eth_maxPriorityFeePerGas falha
+}
+
+func (r *feeRPC) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: r.baseFee}, nil
+}
+
+func (r *feeRPC) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if r.rewards == nil {
+		return nil, errors.New("method not found")
+	}
+	history := &ethereum.FeeHistory{}
+	for _, reward := range r.rewards {
+		history.Reward = append(history.Reward, []*big.Int{reward})
+	}
+	return history, nil
+}
+
+func (r *feeRPC) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	if r.tip == nil {
+		return nil, errors.New("method not found")
+	}
+	return r.tip, nil
+}
+
+func TestFeesFor(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	base := gwei(10)
+
+	tests := []struct {
+		name    string
+		rpc     *feeRPC
+		opts    SponsorOptions
+		tip     *big.Int
+		feeCap  *big.Int
+		wantErr error
+	}{
+		{
+			name:   "median tip",
+			rpc:    &feeRPC{baseFee: base, rewards: []*big.Int{gwei(5), gwei(1), big.NewInt(0), gwei(3)}, tip: gwei(9)},
+			tip:    gwei(3), // zeros (blocos vazios) não entram na mediana
+			feeCap: gwei(23),
+		},
+		{
+			name:   "node suggestion without history",
+			rpc:    &feeRPC{baseFee: base, tip: gwei(4)},
+			tip:    gwei(4),
+			feeCap: gwei(24),
+		},
+		{
+			name:   "default tip",
+			rpc:    &feeRPC{baseFee: base, rewards: []*big.Int{}},
+			tip:    defaultGasTipCap,
+			feeCap: gwei(22),
+		},
+		{
+			name:   "max fee caps the fee cap",
+			rpc:    &feeRPC{baseFee: base, tip: gwei(4)},
+			opts:   SponsorOptions{MaxFeePerGas: gwei(15)},
+			tip:    gwei(4),
+			feeCap: gwei(15),
+		},
+		{
+			name:   "max fee caps the tip",
+			rpc:    &feeRPC{baseFee: base, tip: gwei(12)},
+			opts:   SponsorOptions{MaxFeePerGas: gwei(11)},
+			tip:    gwei(11),
+			feeCap: gwei(11),
+		},
+		{
+			name:    "max fee below base fee",
+			rpc:     &feeRPC{baseFee: base, tip: gwei(4)},
+			opts:    SponsorOptions{MaxFeePerGas: gwei(9)},
+			wantErr: ErrMaxFeeTooLow,
+		},
+		{
+			name:    "strict max fee refuses",
+			rpc:     &feeRPC{baseFee: base, tip: gwei(4)},
+			opts:    SponsorOptions{MaxFeePerGas: gwei(15), StrictMaxFee: true},
+			wantErr: ErrGasPriceTooHigh,
+		},
+		{
+			name:   "strict max fee above fee cap",
+			rpc:    &feeRPC{baseFee: base, tip: gwei(4)},
+			opts:   SponsorOptions{MaxFeePerGas: gwei(30), StrictMaxFee: true},
+			tip:    gwei(4),
+			feeCap: gwei(24),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DelegationService{RPC: tt.rpc, BaseFeeMultiplier: DefaultBaseFeeMultiplier}
+			fees, err := d.feesFor(context.Background(), tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fees.GasTipCap.Cmp(tt.tip) != 0 || fees.GasFeeCap.Cmp(tt.feeCap) != 0 {
+				t.Fatalf("tip %s, fee cap %s, want %s and %s", fees.GasTipCap, fees.GasFeeCap, tt.tip, tt.feeCap)
+			}
+			if fees.GasTipCap.Cmp(fees.GasFeeCap) > 0 {
+				t.Fatal("tip above fee cap")
+			}
+		})
+	}
+}
+
+func TestSuggestFeesRequiresBaseFee(t *testing.T) {
+	d := &DelegationService{RPC: &feeRPC{tip: big.NewInt(1)}}
+	if _, err := d.suggestFees(context.Background(), nil); err == nil {
+		t.Fatal("expected error for a chain without base fee")
+	}
+}
@@ -0,0 +1,129 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultGasMarginPercent é a margem de segurança aplicada sobre a estimativa
+const DefaultGasMarginPercent uint64 = 20
+
+// Fallback quando o node não estima SetCodeTx (sem suporte a tipo 4 / AuthList)
+const (
+	fallbackCallGas      uint64 = 1_000_000 // call única sem gas_limit
+	fallbackMulticallGas uint64 = 100_000   // overhead do execute
+	fallbackPerCallGas   uint64 = 50_000    // por call sem gas_limit
+)
+
+// GasSource indica de onde veio o gas limit
+type GasSource string
+
+const (
+	GasFromEstimate GasSource = "estimate" // eth_estimateGas com a AuthList aplicada
+	GasFromHints    GasSource = "hints"    // gas_limit das calls (estimativa indisponível)
+	GasFromFallback GasSource = "fallback" // heurística fixa (estimativa indisponível)
+)
+
+// GasEstimate descreve o gas limit escolhido para uma SetCodeTx
+type GasEstimate struct {
+	Estimated uint64    `json:"estimated"` // valor bruto do node (0 se indisponível)
+	Floor     uint64    `json:"floor"`     // intrínseco + gas_limit das calls
+	Limit     uint64    `json:"limit"`     // gas limit final, com margem
+	Source    GasSource `json:"source"`
+
+	AccessList *AccessListReport `json:"access_list,omitempty"` // só quando pedida (opt-in)
+}
+
+// estimateSetCodeGas estima o gas da SetCodeTx com a AuthList aplicada.
+// Os gas_limit das calls funcionam como piso; a margem é aplicada sobre o maior valor.
+// Só se o node não souber estimar tipo 4 (método ou tipo sem suporte) usa os hints ou a
+// heurística antiga; qualquer outra falha volta como erro.
+func (d *DelegationService) estimateSetCodeGas(ctx context.Context, from, to common.Address, auths []*Authorization, txData []byte, calls []Call) (*GasEstimate, error) {
+	authList := make([]types.SetCodeAuthorization, len(auths))
+	for i, auth := range auths {
+		authList[i] = auth.toSetCodeAuthorization()
+	}
+
+	intrinsic, err := setCodeIntrinsicGas(txData, authList)
+	if err != nil {
+		return nil, err
+	}
+
+	// Piso: intrínseco + soma dos gas_limit informados
+	floor := intrinsic
+	hinted := len(calls) > 0
+	for _, call := range calls {
+		if call.GasLimit == 0 {
+			hinted = false
+			continue
+		}
+		floor += call.GasLimit
+	}
+
+	est := &GasEstimate{Floor: floor}
+	estimated, err := d.RPC.EstimateGas(ctx, ethereum.CallMsg{
+		From:              from,
+		To:                &to,
+		Data:              txData,
+		AuthorizationList: authList,
+	})
+	switch {
+	case err == nil:
+		est.Estimated = estimated
+		est.Source = GasFromEstimate
+		est.Limit = d.withGasMargin(max(estimated, floor))
+	case !errors.Is(ClassifyRPCError(err), ErrUnsupported):
+		// Revert, timeout, saldo insuficiente, node fora do ar: gas fixo só esconderia a falha
+		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	case hinted:
+		est.Source = GasFromHints
+		est.Limit = d.withGasMargin(floor)
+	default:
+		est.Source = GasFromFallback
+		est.Limit = d.withGasMargin(max(fallbackGas(intrinsic, calls), floor))
+	}
+	return est, nil
+}
+
+// withGasMargin soma a margem de segurança configurada
+func (d *DelegationService) withGasMargin(gas uint64) uint64 {
+	return gas + gas*d.GasMarginPercent/100
+}
+
+// setCodeIntrinsicGas calcula o custo intrínseco (Prague) de uma SetCodeTx
+func setCodeIntrinsicGas(txData []byte, authList []types.SetCodeAuthorization) (uint64, error) {
+	gas, err := core.IntrinsicGas(txData, nil, authList, false, true, true, true)
+	if err != nil {
+		return 0, err
+	}
+	// EIP-7623: calldata pesada paga o piso de dados
+	floorData, err := core.FloorDataGas(txData)
+	if err != nil {
+		return 0, err
+	}
+	return max(gas, floorData), nil
+}
+
+// fallbackGas reproduz a heurística fixa para nodes sem estimativa de tipo 4
+func fallbackGas(intrinsic uint64, calls []Call) uint64 {
+	switch len(calls) {
+	case 0:
+		return intrinsic // só a AuthList (lotes e revogação)
+	case 1:
+		return fallbackCallGas
+	default:
+		return fallbackMulticallGas + uint64(len(calls))*fallbackPerCallGas
+	}
+}
+
+// isExecutionReverted identifica erros de revert (a estimativa funcionou, a execução não)
+func isExecutionReverted(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "execution reverted")
+}
@@ -0,0 +1,86 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// jsonRPCError é um erro JSON-RPC respondido pelo node
+type jsonRPCError struct {
+	code int
+	msg  string
+}
+
+func (e jsonRPCError) Error() string  { return e.msg }
+func (e jsonRPCError) ErrorCode() int { return e.code }
+
+// estimateRPC responde eth_estimateGas com um valor fixo ou um erro
+type estimateRPC struct {
+	EthClient
+	gas uint64
+	err error
+}
+
+func (r *estimateRPC) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return r.gas, r.err
+}
+
+func TestEstimateSetCodeGas(t *testing.T) {
+	authority := common.Address{4}
+	auth := &Authorization{ChainID: 1337, Address: common.Address{1}, Signer: authority}
+	intrinsic, err := setCodeIntrinsicGas(nil, []types.SetCodeAuthorization{auth.toSetCodeAuthorization()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	margin := func(gas uint64) uint64 { return gas + gas*DefaultGasMarginPercent/100 }
+
+	// gas_limit chega pelo JSON das calls
+	hinted, err := parseCalls([]CallData{{To: common.Address{2}.Hex(), Data: "0x", Value: "0", GasLimit: 70_000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unhinted := []Call{{To: common.Address{2}, Value: big.NewInt(0)}}
+
+	tests := []struct {
+		name   string
+		gas    uint64
+		err    error
+		calls  []Call
+		source GasSource
+		limit  uint64
+		fails  bool
+	}{
+		{name: "estimate", gas: 100_000, calls: unhinted, source: GasFromEstimate, limit: margin(100_000)},
+		{name: "hint above estimate", gas: 10_000, calls: hinted, source: GasFromEstimate, limit: margin(intrinsic + 70_000)},
+		{name: "method not found", err: jsonRPCError{-32601, "the method eth_estimateGas does not exist/is not available"}, calls: unhinted, source: GasFromFallback, limit: margin(fallbackCallGas)},
+		{name: "type not supported hints", err: jsonRPCError{-32000, "transaction type not supported"}, calls: hinted, source: GasFromHints, limit: margin(intrinsic + 70_000)},
+		{name: "reverted", err: jsonRPCError{3, "execution reverted"}, calls: unhinted, fails: true},
+		{name: "insufficient funds", err: jsonRPCError{-32000, "insufficient funds for gas * price + value"}, calls: unhinted, fails: true},
+		{name: "node unreachable", err: errors.New("dial tcp: connection refused"), calls: unhinted, fails: true},
+		{name: "timeout", err: context.DeadlineExceeded, calls: unhinted, fails: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDelegationService(big.NewInt(1337), &estimateRPC{gas: tt.gas, err: tt.err})
+			est, err := d.estimateSetCodeGas(context.Background(), common.Address{3}, authority, []*Authorization{auth}, nil, tt.calls)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("estimate = %+v, want an error", est)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if est.Source != tt.source || est.Limit != tt.limit {
+				t.Fatalf("estimate = %+v, want %s with limit %d", est, tt.source, tt.limit)
+			}
+		})
+	}
+}
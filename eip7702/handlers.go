@@ -1,26 +1,37 @@
 package eip7702
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/go-chi/chi/v5"
 )
 
 // DelegationHandlers contém os handlers HTTP para EIP-7702
 type DelegationHandlers struct {
-	svc *DelegationService
+	svc      *DelegationService
+	sponsors *SponsorRegistry // chaves dos sponsors ficam no servidor
 }
 
-func NewDelegationHandlers(service *DelegationService) *DelegationHandlers {
-	return &DelegationHandlers{svc: service}
+func NewDelegationHandlers(service *DelegationService, sponsors *SponsorRegistry) *DelegationHandlers {
+	return &DelegationHandlers{svc: service, sponsors: sponsors}
+}
+
+// sponsor escolhe o sponsor configurado pelo nome (vazio = padrão)
+func (h *DelegationHandlers) sponsor(name string) (Signer, error) {
+	return h.sponsors.Get(name)
 }
 
 // Routes retorna as rotas HTTP para EIP-7702
@@ -29,7 +40,14 @@ func (h *DelegationHandlers) Routes() http.Handler {
 
 	// ===== ROTAS BÁSICAS =====
 	r.Post("/authorize", h.handleAuthorize)
+	r.Post("/authorize/prepare", h.handleAuthorizePrepare)
+	r.Post("/authorize/submit", h.handleAuthorizeSubmit)
 	r.Post("/sponsor", h.handleSponsor)
+	r.Post("/sponsor-secure", h.handleSponsorSecure)
+	r.Post("/sponsor-batch", h.handleSponsorBatch)
+	r.Post("/self-execute", h.handleSelfExecute)
+	r.Post("/revoke", h.handleRevoke)
+	r.Post("/simulate", h.handleSimulate)
 
 	// ===== ROTAS ESPECÍFICAS =====
 	r.Post("/sponsor-eth", h.handleSponsorETH)
@@ -47,6 +65,12 @@ func (h *DelegationHandlers) Routes() http.Handler {
 
 	// ===== ROTAS DE INFO =====
 	r.Get("/contracts", h.handleGetContracts)
+	r.Get("/sponsors", h.handleGetSponsors)
+	r.Get("/delegation/{address}", h.handleDelegationStatus)
+	r.Get("/authorizations/{id}", h.handleAuthorizationStatus)
+	r.Get("/tx/{hash}", h.handleTxStatus)
+	r.Post("/tx/{hash}/speedup", h.handleSpeedUp)
+	r.Post("/tx/{hash}/cancel", h.handleCancel)
 
 	return r
 }
@@ -57,17 +81,214 @@ func parsePrivateKey(pkHex string) (*ecdsa.PrivateKey, error) {
 	return crypto.HexToECDSA(pkHex)
 }
 
+// parseKeySigner cria um Signer em memória a partir da chave hex
+func parseKeySigner(pkHex string) (Signer, error) {
+	key, err := parsePrivateKey(pkHex)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key), nil
+}
+
+// parseCalls converte CallData (JSON) para Call
+func parseCalls(in []CallData) ([]Call, error) {
+	calls := make([]Call, len(in))
+	for i, callData := range in {
+		// Validar endereço
+		if !common.IsHexAddress(callData.To) {
+			return nil, fmt.Errorf("Invalid address in call %d", i)
+		}
+
+		// Converter dados hex
+		data, err := hex.DecodeString(strings.TrimPrefix(callData.Data, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid data in call %d", i)
+		}
+
+		// Converter valor
+		value, ok := new(big.Int).SetString(callData.Value, 10)
+		if !ok {
+			value = big.NewInt(0)
+		}
+
+		calls[i] = Call{
+			To:       common.HexToAddress(callData.To),
+			Data:     data,
+			Value:    value,
+			GasLimit: callData.GasLimit,
+		}
+	}
+	return calls, nil
+}
+
+// parseSponsorOptions lê o teto de fee do request (wei, decimal; vazio = sem teto) e o flag simulate
+func parseSponsorOptions(maxFeePerGas string, simulate bool) (SponsorOptions, error) {
+	opts := SponsorOptions{Simulate: simulate}
+	if maxFeePerGas == "" {
+		return opts, nil
+	}
+	v, ok := new(big.Int).SetString(maxFeePerGas, 10)
+	if !ok || v.Sign() <= 0 {
+		return opts, fmt.Errorf("Invalid max_fee_per_gas %q", maxFeePerGas)
+	}
+	opts.MaxFeePerGas = v
+	return opts, nil
+}
+
+// writeExecuteError responde falhas de execução; revert previsto na simulação ou no preflight vira 422
+func writeExecuteError(w http.ResponseWriter, err error) {
+	var simErr *SimulationError
+	var preErr *PreflightError
+	switch {
+	case errors.As(err, &simErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      err.Error(),
+			"simulation": simErr.Result,
+		})
+	case errors.As(err, &preErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":     err.Error(),
+			"preflight": preErr.Failures,
+		})
+	case errors.Is(err, ErrMaxFeeTooLow), errors.Is(err, ErrZeroContract), errors.Is(err, ErrUntrustedContract):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrDeadlineExceeded), errors.Is(err, ErrGasPriceTooHigh), errors.Is(err, ErrUnexpectedValue):
+		// Garantias do request seguro: nada foi enviado
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		if !writeRPCError(w, "Failed to execute sponsored transaction", err) {
+			http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// rpcErrorStatus é o status HTTP de cada recusa do node
+var rpcErrorStatus = map[RPCErrorCode]int{
+	CodeNonceTooLow:            http.StatusConflict,
+	CodeReplacementUnderpriced: http.StatusConflict,
+	CodeTxAlreadyKnown:         http.StatusConflict,
+	CodeFeeTooLow:              http.StatusUnprocessableEntity,
+	CodeInsufficientFunds:      http.StatusUnprocessableEntity,
+	CodeIntrinsicGasTooLow:     http.StatusUnprocessableEntity,
+	CodeAuthorizationRejected:  http.StatusUnprocessableEntity,
+	CodeExecutionReverted:      http.StatusUnprocessableEntity,
+	CodeRPCUnavailable:         http.StatusServiceUnavailable,
+	CodeUnsupported:            http.StatusBadGateway,
+	CodeRPCError:               http.StatusBadGateway,
+}
+
+// writeRPCError responde erros do node como JSON {"error", "code"}; false se err
+// não veio do RPC (quem chama trata)
+func writeRPCError(w http.ResponseWriter, prefix string, err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) && (!nodeAnswered(err) || !errors.As(ClassifyRPCError(err), &rpcErr)) {
+		return false
+	}
+	status, ok := rpcErrorStatus[rpcErr.Code]
+	if !ok {
+		status = http.StatusBadGateway
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": fmt.Sprintf("%s: %v", prefix, err),
+		"code":  rpcErr.Code,
+	})
+	return true
+}
+
+// writeSendError responde a falha no envio da tx
+func writeSendError(w http.ResponseWriter, err error) {
+	if !writeRPCError(w, "Failed to send transaction", err) {
+		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// shouldBroadcast: broadcast ausente = enviar (comportamento padrão)
+func shouldBroadcast(broadcast *bool) bool {
+	return broadcast == nil || *broadcast
+}
+
+// writePreview responde a tx assinada sem enviá-la (broadcast: false). A raw tx pode
+// ser enviada por fora: o nonce e as autorizações ficam presos a ela.
+// extra leva os campos específicos da rota
+func (h *DelegationHandlers) writePreview(w http.ResponseWriter, tx *types.Transaction, extra map[string]interface{}) {
+	preview, err := h.svc.PreviewTransaction(tx)
+	if err != nil {
+		h.svc.DiscardTransaction(tx)
+		http.Error(w, fmt.Sprintf("Failed to build transaction preview: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := h.svc.HandOffTransaction(tx); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to hand off transaction: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"broadcast":   false,
+		"tx_hash":     tx.Hash().Hex(),
+		"transaction": preview,
+	}
+	for k, v := range extra {
+		resp[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// withAccessList inclui o relatório da access list na resposta quando ela foi pedida
+func withAccessList(resp map[string]interface{}, gas *GasEstimate) map[string]interface{} {
+	if gas != nil && gas.AccessList != nil {
+		resp["access_list"] = gas.AccessList
+	}
+	return resp
+}
+
+// resolveAuthorization usa a autorização pré-assinada pelo cliente ou, na falta dela,
+// assina com signer_pk. Retorna o status HTTP adequado em caso de erro.
+func (h *DelegationHandlers) resolveAuthorization(ctx context.Context, signerPK string, preSigned *Authorization, contractAddr common.Address) (*Authorization, int, error) {
+	if preSigned != nil {
+		if err := h.svc.VerifyAuthorization(preSigned); err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("Invalid authorization signature: %v", err)
+		}
+		if preSigned.Address != contractAddr {
+			return nil, http.StatusBadRequest, fmt.Errorf("Authorization delegates to %s, expected %s", preSigned.Address.Hex(), contractAddr.Hex())
+		}
+		return preSigned, 0, nil
+	}
+
+	sk, err := parseKeySigner(signerPK)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("Invalid signer private key: %v", err)
+	}
+
+	auth, err := h.svc.SignDelegation(ctx, contractAddr, sk)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to create authorization: %v", err)
+	}
+	return auth, 0, nil
+}
+
 // Struct reutilizável para requests básicos
 type BasicSponsorRequest struct {
-	SignerPK  string `json:"signer_pk"`
-	SponsorPK string `json:"sponsor_pk"`
-	Recipient string `json:"recipient"`
-	Amount    string `json:"amount"`
+	SignerPK      string         `json:"signer_pk,omitempty"`
+	Authorization *Authorization `json:"authorization,omitempty"` // Autorização pré-assinada (substitui signer_pk)
+	Sponsor       string         `json:"sponsor,omitempty"`       // Nome do sponsor configurado (vazio = padrão)
+	Recipient     string         `json:"recipient"`
+	Amount        string         `json:"amount"`
+	MaxFeePerGas  string         `json:"max_fee_per_gas,omitempty"` // Teto do fee por gas em wei (opcional)
+	Simulate      bool           `json:"simulate,omitempty"`        // eth_call antes de assinar; revert não é enviado
+	Broadcast     *bool          `json:"broadcast,omitempty"`       // false = devolve a tx assinada sem enviar
+	AccessList    bool           `json:"access_list,omitempty"`     // anexa access list se reduzir o gas
 }
 
 // Validação para a struct
 func (req *BasicSponsorRequest) Validate() error {
-	if req.SignerPK == "" || req.SponsorPK == "" || req.Recipient == "" || req.Amount == "" {
+	if (req.SignerPK == "" && req.Authorization == nil) || req.Recipient == "" || req.Amount == "" {
 		return fmt.Errorf("missing required fields")
 	}
 	if !common.IsHexAddress(req.Recipient) {
@@ -79,23 +300,29 @@ func (req *BasicSponsorRequest) Validate() error {
 // handleSponsorGeneric - ROTA PRINCIPAL GENÉRICA
 func (h *DelegationHandlers) handleSponsorGeneric(w http.ResponseWriter, r *http.Request) {
 	var in struct {
-		SignerPK          string        `json:"signer_pk"`
-		SponsorPK         string        `json:"sponsor_pk"`
-		ContractAddress   string        `json:"contract_address"`   // SimpleDelegateContract
-		FunctionSignature string        `json:"function_signature"` // Função DO SimpleDelegateContract
-		Parameters        []interface{} `json:"parameters"`         // Parâmetros da função
+		SignerPK          string         `json:"signer_pk"`
+		Authorization     *Authorization `json:"authorization,omitempty"` // Autorização pré-assinada (substitui signer_pk)
+		Sponsor           string         `json:"sponsor,omitempty"`       // Nome do sponsor configurado
+		ContractAddress   string         `json:"contract_address"`        // SimpleDelegateContract
+		FunctionSignature string         `json:"function_signature"`      // Função DO SimpleDelegateContract
+		Parameters        []interface{}  `json:"parameters"`              // Parâmetros da função
+		MaxFeePerGas      string         `json:"max_fee_per_gas,omitempty"`
+		Simulate          bool           `json:"simulate,omitempty"`
+		Broadcast         *bool          `json:"broadcast,omitempty"`
+		AccessList        bool           `json:"access_list,omitempty"`
 	}
 	json.NewDecoder(r.Body).Decode(&in)
 
-	sk, err := parsePrivateKey(in.SignerPK)
+	opts, err := parseSponsorOptions(in.MaxFeePerGas, in.Simulate)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid signer private key: %v", err), 400)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	opts.AccessList = in.AccessList
 
-	sp, err := parsePrivateKey(in.SponsorPK)
+	sp, err := h.sponsor(in.Sponsor)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid sponsor private key: %v", err), 400)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -105,9 +332,9 @@ func (h *DelegationHandlers) handleSponsorGeneric(w http.ResponseWriter, r *http
 	}
 
 	// Autorizar o contrato especificado
-	auth, err := h.svc.SignDelegation(common.HexToAddress(in.ContractAddress), sk)
+	auth, status, err := h.resolveAuthorization(r.Context(), in.SignerPK, in.Authorization, common.HexToAddress(in.ContractAddress))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create authorization: %v", err), 500)
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -123,23 +350,33 @@ func (h *DelegationHandlers) handleSponsorGeneric(w http.ResponseWriter, r *http
 		Data: common.Hex2Bytes(strings.TrimPrefix(cd, "0x")),
 	}
 
-	tx, err := h.svc.ExecuteSponsored(auth, []Call{call}, sp)
+	tx, gas, err := h.svc.ExecuteSponsored(r.Context(), auth, []Call{call}, sp, opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), 500)
+		writeExecuteError(w, err)
 		return
 	}
 
-	if err := h.svc.RPC.SendTransaction(tx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), 500)
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(in.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{
+			"authorization": auth,
+			"call_data":     cd,
+			"function":      in.FunctionSignature,
+		}, gas))
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{
 		"tx_hash":       tx.Hash().Hex(),
 		"authorization": auth,
 		"call_data":     cd,
 		"function":      in.FunctionSignature,
-	})
+	}, gas))
 }
 
 // handleSponsorMint - Rota específica para mint
@@ -156,15 +393,16 @@ func (h *DelegationHandlers) handleSponsorMint(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	sk, err := parsePrivateKey(req.SignerPK)
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, req.Simulate)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid signer private key: %v", err), 400)
+		http.Error(w, err.Error(), 400)
 		return
 	}
+	opts.AccessList = req.AccessList
 
-	sp, err := parsePrivateKey(req.SponsorPK)
+	sp, err := h.sponsor(req.Sponsor)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid sponsor private key: %v", err), 400)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -174,16 +412,16 @@ func (h *DelegationHandlers) handleSponsorMint(w http.ResponseWriter, r *http.Re
 	}
 
 	// Autorizar SimpleDelegateContract
-	auth, err := h.svc.SignDelegation(common.HexToAddress(DelegateContract), sk)
+	auth, status, err := h.resolveAuthorization(r.Context(), req.SignerPK, req.Authorization, h.svc.DelegateAddress)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create authorization: %v", err), 500)
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	// Chamar SimpleDelegateContract.mint(token, to, amount)
 	amtWei := TokenAmountToWei(req.Amount, 18)
 	cd := (&CallDataBuilder{}).Mint(
-		common.HexToAddress(TokenContract),
+		h.svc.TokenAddress,
 		common.HexToAddress(req.Recipient),
 		amtWei,
 	)
@@ -192,19 +430,25 @@ func (h *DelegationHandlers) handleSponsorMint(w http.ResponseWriter, r *http.Re
 		Data: common.Hex2Bytes(strings.TrimPrefix(cd, "0x")),
 	}
 
-	tx, err := h.svc.ExecuteSponsored(auth, []Call{call}, sp)
+	tx, gas, err := h.svc.ExecuteSponsored(r.Context(), auth, []Call{call}, sp, opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), 500)
+		writeExecuteError(w, err)
+		return
+	}
+
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{"authorization": auth}, gas))
 		return
 	}
 
-	if err := h.svc.RPC.SendTransaction(tx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), 500)
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"tx_hash": tx.Hash().Hex()})
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{"tx_hash": tx.Hash().Hex()}, gas))
 }
 
 // handleSponsorTransfer - Rota específica para transfer
@@ -221,15 +465,16 @@ func (h *DelegationHandlers) handleSponsorTransfer(w http.ResponseWriter, r *htt
 		return
 	}
 
-	sk, err := parsePrivateKey(req.SignerPK)
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, req.Simulate)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid signer private key: %v", err), 400)
+		http.Error(w, err.Error(), 400)
 		return
 	}
+	opts.AccessList = req.AccessList
 
-	sp, err := parsePrivateKey(req.SponsorPK)
+	sp, err := h.sponsor(req.Sponsor)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid sponsor private key: %v", err), 400)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -239,16 +484,16 @@ func (h *DelegationHandlers) handleSponsorTransfer(w http.ResponseWriter, r *htt
 	}
 
 	// Autorizar SimpleDelegateContract
-	auth, err := h.svc.SignDelegation(common.HexToAddress(DelegateContract), sk)
+	auth, status, err := h.resolveAuthorization(r.Context(), req.SignerPK, req.Authorization, h.svc.DelegateAddress)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create authorization: %v", err), 500)
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	// CORRIGIDO: Chamar SimpleDelegateContract.transfer(token, to, amount)
 	amtWei := TokenAmountToWei(req.Amount, 18)
 	cd := (&CallDataBuilder{}).Transfer(
-		common.HexToAddress(TokenContract),
+		h.svc.TokenAddress,
 		common.HexToAddress(req.Recipient),
 		amtWei,
 	)
@@ -257,19 +502,25 @@ func (h *DelegationHandlers) handleSponsorTransfer(w http.ResponseWriter, r *htt
 		Data: common.Hex2Bytes(strings.TrimPrefix(cd, "0x")),
 	}
 
-	tx, err := h.svc.ExecuteSponsored(auth, []Call{call}, sp)
+	tx, gas, err := h.svc.ExecuteSponsored(r.Context(), auth, []Call{call}, sp, opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), 500)
+		writeExecuteError(w, err)
 		return
 	}
 
-	if err := h.svc.RPC.SendTransaction(tx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), 500)
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{"authorization": auth}, gas))
+		return
+	}
+
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"tx_hash": tx.Hash().Hex()})
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{"tx_hash": tx.Hash().Hex()}, gas))
 }
 
 // handleBuildGeneric - Helper para construir call data genérico
@@ -303,13 +554,174 @@ func (h *DelegationHandlers) handleBuildGeneric(w http.ResponseWriter, r *http.R
 func (h *DelegationHandlers) handleGetContracts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"token_contract":           TokenContract,
-		"simple_delegate_contract": DelegateContract,
-		"network":                  "holesky",
-		"chain_id":                 17000,
+		"token_contract":           h.svc.TokenAddress.Hex(),
+		"simple_delegate_contract": h.svc.DelegateAddress.Hex(),
+		"chain_id":                 h.svc.ChainID,
+	})
+}
+
+// handleGetSponsors - lista os sponsors configurados (nome e endereço, nunca a chave)
+func (h *DelegationHandlers) handleGetSponsors(w http.ResponseWriter, r *http.Request) {
+	sponsors := h.sponsors.List()
+	nonces := make(map[string]NonceStatus, len(sponsors))
+	if h.svc.Nonces != nil {
+		for _, sp := range sponsors {
+			nonces[sp.Name] = h.svc.Nonces.Status(sp.Address)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sponsors": sponsors,
+		"nonces":   nonces,
 	})
 }
 
+// handleDelegationStatus - informa se a EOA está delegada e para qual contrato
+func (h *DelegationHandlers) handleDelegationStatus(w http.ResponseWriter, r *http.Request) {
+	addr := chi.URLParam(r, "address")
+	if !common.IsHexAddress(addr) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	info, err := h.svc.DelegationStatus(r.Context(), common.HexToAddress(addr))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get delegation status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// handleAuthorizationStatus mostra o ciclo de vida (issued/consumed/invalidated) de uma autorização
+func (h *DelegationHandlers) handleAuthorizationStatus(w http.ResponseWriter, r *http.Request) {
+	raw, err := hexutil.Decode(chi.URLParam(r, "id"))
+	if err != nil || len(raw) != common.HashLength {
+		http.Error(w, "Invalid authorization id", http.StatusBadRequest)
+		return
+	}
+
+	rec, err := h.svc.AuthorizationRecordByID(common.BytesToHash(raw))
+	if errors.Is(err, ErrAuthorizationNotFound) {
+		http.Error(w, "Authorization not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get authorization: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// handleTxStatus - status, bloco, gas usado, preço efetivo e eventos decodificados da tx
+func (h *DelegationHandlers) handleTxStatus(w http.ResponseWriter, r *http.Request) {
+	raw, err := hexutil.Decode(chi.URLParam(r, "hash"))
+	if err != nil || len(raw) != common.HashLength {
+		http.Error(w, "Invalid transaction hash", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.svc.TransactionStatus(r.Context(), common.BytesToHash(raw))
+	if errors.Is(err, ErrTxNotFound) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get transaction status: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// ReplaceRequest é o payload (opcional) de /tx/{hash}/speedup e /tx/{hash}/cancel
+type ReplaceRequest struct {
+	MaxFeePerGas string `json:"max_fee_per_gas,omitempty"` // wei; vazio = sem teto
+}
+
+// handleSpeedUp - reenvia a SetCodeTx pendente no mesmo nonce com fees maiores
+func (h *DelegationHandlers) handleSpeedUp(w http.ResponseWriter, r *http.Request) {
+	h.handleReplace(w, r, h.svc.SpeedUp)
+}
+
+// handleCancel - ocupa o nonce da tx pendente com uma transferência de 0 ETH do sponsor para si mesmo
+func (h *DelegationHandlers) handleCancel(w http.ResponseWriter, r *http.Request) {
+	h.handleReplace(w, r, h.svc.Cancel)
+}
+
+// handleReplace resolve a tx e o sponsor que a enviou e aplica a substituição
+func (h *DelegationHandlers) handleReplace(w http.ResponseWriter, r *http.Request, replace func(context.Context, common.Hash, Signer, SponsorOptions) (*ReplacementResult, error)) {
+	raw, err := hexutil.Decode(chi.URLParam(r, "hash"))
+	if err != nil || len(raw) != common.HashLength {
+		http.Error(w, "Invalid transaction hash", http.StatusBadRequest)
+		return
+	}
+	hash := common.BytesToHash(raw)
+
+	var req ReplaceRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.svc.TransactionStatus(r.Context(), hash)
+	if errors.Is(err, ErrTxNotFound) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get transaction status: %v", err), http.StatusBadGateway)
+		return
+	}
+	sp, ok := h.sponsors.ByAddress(status.From)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Transaction sender %s is not a configured sponsor", status.From.Hex()), http.StatusConflict)
+		return
+	}
+
+	result, err := replace(r.Context(), hash, sp, opts)
+	switch {
+	case errors.Is(err, ErrTxNotFound):
+		http.Error(w, "Transaction was not sent by this service", http.StatusNotFound)
+		return
+	case errors.Is(err, ErrTxNotReplaceable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, ErrReplacementFeeTooLow):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		if !writeRPCError(w, "Failed to replace transaction", err) {
+			http.Error(w, fmt.Sprintf("Failed to replace transaction: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// authorizationExpiry devolve a expiração definida pelo servidor (nil se não registrada)
+func (h *DelegationHandlers) authorizationExpiry(auth *Authorization) *time.Time {
+	rec, err := h.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		return nil
+	}
+	return &rec.ExpiresAt
+}
+
 // handleAuthorize cria uma autorização assinada para um contrato
 func (h *DelegationHandlers) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 	var req AuthorizeRequest
@@ -326,14 +738,14 @@ func (h *DelegationHandlers) handleAuthorize(w http.ResponseWriter, r *http.Requ
 	contractAddr := common.HexToAddress(req.ContractAddress)
 
 	// Validar e processar chave privada
-	privateKey, err := parsePrivateKey(strings.TrimPrefix(req.SignerPK, "0x"))
+	signer, err := parseKeySigner(req.SignerPK)
 	if err != nil {
 		http.Error(w, "Invalid private key", http.StatusBadRequest)
 		return
 	}
 
 	// Criar autorização
-	auth, err := h.svc.SignDelegation(contractAddr, privateKey)
+	auth, err := h.svc.SignDelegationWithOptions(r.Context(), contractAddr, signer, DelegationOptions{Universal: req.Universal})
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to create authorization: %v", err), http.StatusInternalServerError)
 		return
@@ -342,8 +754,62 @@ func (h *DelegationHandlers) handleAuthorize(w http.ResponseWriter, r *http.Requ
 	// Retornar autorização
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"authorization":  auth,
-		"signer_address": crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		"authorization":    auth,
+		"authorization_id": AuthorizationID(auth).Hex(),
+		"expires_at":       h.authorizationExpiry(auth),
+		"signer_address":   signer.Address().Hex(),
+		"warnings":         h.svc.AuthorizationWarnings(auth),
+	})
+}
+
+// handleAuthorizePrepare devolve chain_id, nonce e o hash para a authority assinar no cliente
+func (h *DelegationHandlers) handleAuthorizePrepare(w http.ResponseWriter, r *http.Request) {
+	var req PrepareAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if !common.IsHexAddress(req.Authority) {
+		http.Error(w, "Invalid authority address", http.StatusBadRequest)
+		return
+	}
+	if !common.IsHexAddress(req.ContractAddress) {
+		http.Error(w, "Invalid contract address", http.StatusBadRequest)
+		return
+	}
+
+	opts := DelegationOptions{SelfSponsored: req.SelfSponsored, Universal: req.Universal}
+	prepared, err := h.svc.PrepareAuthorization(r.Context(), common.HexToAddress(req.Authority), common.HexToAddress(req.ContractAddress), opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to prepare authorization: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prepared)
+}
+
+// handleAuthorizeSubmit recebe a assinatura (y_parity, r, s) e devolve a Authorization validada
+func (h *DelegationHandlers) handleAuthorizeSubmit(w http.ResponseWriter, r *http.Request) {
+	var req SubmitAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	auth, err := h.svc.SubmitAuthorization(r.Context(), &req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid authorization: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"authorization":    auth,
+		"authorization_id": AuthorizationID(auth).Hex(),
+		"expires_at":       h.authorizationExpiry(auth),
+		"warnings":         h.svc.AuthorizationWarnings(auth),
 	})
 }
 
@@ -356,70 +822,410 @@ func (h *DelegationHandlers) handleSponsor(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Validar chave privada do sponsor
-	sponsorPK, err := parsePrivateKey(strings.TrimPrefix(req.SponsorPK, "0x"))
+	sponsor, err := h.sponsor(req.Sponsor)
 	if err != nil {
-		http.Error(w, "Invalid sponsor private key", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Verificar assinatura antes de qualquer outra coisa: Signer vem do cliente
+	if err := h.svc.VerifyAuthorization(&req.Authorization); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid authorization signature: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	sponsorAddr := crypto.PubkeyToAddress(sponsorPK.PublicKey)
+	sponsorAddr := sponsor.Address()
 	fmt.Printf("Sponsor address: %s\n", sponsorAddr.Hex())
 	fmt.Printf("Signer address: %s\n", req.Authorization.Signer.Hex())
 
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, req.Simulate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.AccessList = req.AccessList
+
 	// Converter CallData para Call
-	calls := make([]Call, len(req.Calls))
-	for i, callData := range req.Calls {
-		// Validar endereço
-		if !common.IsHexAddress(callData.To) {
-			http.Error(w, fmt.Sprintf("Invalid address in call %d", i), http.StatusBadRequest)
+	calls, err := parseCalls(req.Calls)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i, call := range calls {
+		fmt.Printf("Call %d: to=%s, dataLen=%d\n", i, call.To.Hex(), len(call.Data))
+	}
+
+	// Executar transação patrocinada
+	tx, gas, err := h.svc.ExecuteSponsored(r.Context(), &req.Authorization, calls, sponsor, opts)
+	if err != nil {
+		writeExecuteError(w, err)
+		return
+	}
+
+	fmt.Printf("Transaction created: %s\n", tx.Hash().Hex())
+
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{
+			"sponsor":  sponsorAddr.Hex(),
+			"warnings": h.svc.AuthorizationWarnings(&req.Authorization),
+		}, gas))
+		return
+	}
+
+	// Enviar transação para a rede
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	fmt.Printf("Transaction sent successfully: %s\n", tx.Hash().Hex())
+
+	// Retornar hash da transação
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{
+		"tx_hash":  tx.Hash().Hex(),
+		"sponsor":  sponsorAddr.Hex(),
+		"warnings": h.svc.AuthorizationWarnings(&req.Authorization),
+	}, gas))
+}
+
+// handleSponsorSecure - execução patrocinada que respeita deadline, max_gas_price e expected_value
+func (h *DelegationHandlers) handleSponsorSecure(w http.ResponseWriter, r *http.Request) {
+	var req SecureDelegationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sp, err := h.sponsor(req.Sponsor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	calls, err := parseCalls(req.Calls)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	auth, status, err := h.resolveAuthorization(r.Context(), req.SignerPK, req.Authorization, req.ContractAddress)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	tx, gas, err := h.svc.ExecuteSecure(r.Context(), &req, auth, calls, sp)
+	if err != nil {
+		writeExecuteError(w, err)
+		return
+	}
+
+	resp := withAccessList(map[string]interface{}{
+		"authorization": auth,
+		"sponsor":       sp.Address().Hex(),
+		"warnings":      h.svc.AuthorizationWarnings(auth),
+	}, gas)
+
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, resp)
+		return
+	}
+
+	// Última checagem do deadline antes do broadcast
+	if err := req.CheckDeadline(time.Now()); err != nil {
+		h.svc.DiscardTransaction(tx)
+		writeExecuteError(w, err)
+		return
+	}
+
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	resp["tx_hash"] = tx.Hash().Hex()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleSimulate - roda as calls via eth_call com o designator instalado na authority, sem assinar nada
+func (h *DelegationHandlers) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	var req SimulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var authority, delegate common.Address
+	if req.Authorization != nil {
+		if err := h.svc.VerifyAuthorization(req.Authorization); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid authorization signature: %v", err), http.StatusBadRequest)
 			return
 		}
-
-		// Converter dados hex
-		data, err := hex.DecodeString(strings.TrimPrefix(callData.Data, "0x"))
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Invalid data in call %d", i), http.StatusBadRequest)
+		authority, delegate = req.Authorization.Signer, req.Authorization.Address
+	} else {
+		if !common.IsHexAddress(req.Authority) || !common.IsHexAddress(req.ContractAddress) {
+			http.Error(w, "authority and contract_address (or authorization) are required", http.StatusBadRequest)
 			return
 		}
+		authority, delegate = common.HexToAddress(req.Authority), common.HexToAddress(req.ContractAddress)
+	}
 
-		// Converter valor
-		value, ok := new(big.Int).SetString(callData.Value, 10)
-		if !ok {
-			value = big.NewInt(0)
+	sp, err := h.sponsor(req.Sponsor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	calls, err := parseCalls(req.Calls)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.svc.Simulate(r.Context(), sp.Address(), authority, delegate, calls)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to simulate: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleSponsorBatch - várias autorizações em uma única SetCodeTx patrocinada
+func (h *DelegationHandlers) handleSponsorBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchSponsorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	sponsorPK, err := h.sponsor(req.Sponsor)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	tx, results, err := h.svc.ExecuteSponsoredBatch(r.Context(), req.Authorizations, sponsorPK, opts)
+	if err != nil {
+		if results == nil {
+			http.Error(w, fmt.Sprintf("Failed to execute sponsored batch: %v", err), http.StatusBadRequest)
+			return
 		}
+		// Nenhuma autorização válida: devolve o motivo de cada uma
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   err.Error(),
+			"results": results,
+		})
+		return
+	}
 
-		calls[i] = Call{
-			To:    common.HexToAddress(callData.To),
-			Data:  data,
-			Value: value,
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, map[string]interface{}{
+			"included": len(tx.SetCodeAuthorizations()),
+			"results":  results,
+		})
+		return
+	}
+
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tx_hash":  tx.Hash().Hex(),
+		"included": len(tx.SetCodeAuthorizations()),
+		"results":  results,
+	})
+}
+
+// handleSelfExecute - a authority delega e paga o próprio gas (sender == authority)
+func (h *DelegationHandlers) handleSelfExecute(w http.ResponseWriter, r *http.Request) {
+	var req SelfExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if !common.IsHexAddress(req.ContractAddress) {
+		http.Error(w, "Invalid contract address", http.StatusBadRequest)
+		return
+	}
+
+	sk, err := parseKeySigner(req.SignerPK)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid signer private key: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	calls, err := parseCalls(req.Calls)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.AccessList = req.AccessList
+
+	tx, auth, gas, err := h.svc.ExecuteSelf(r.Context(), common.HexToAddress(req.ContractAddress), calls, sk, req.Universal, opts)
+	if err != nil {
+		if !writeRPCError(w, "Failed to execute self-sponsored transaction", err) {
+			http.Error(w, fmt.Sprintf("Failed to execute self-sponsored transaction: %v", err), http.StatusInternalServerError)
 		}
+		return
+	}
 
-		fmt.Printf("Call %d: to=%s, dataLen=%d\n", i, calls[i].To.Hex(), len(calls[i].Data))
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{
+			"authorization": auth,
+			"sender":        auth.Signer.Hex(),
+		}, gas))
+		return
 	}
 
-	// Executar transação patrocinada
-	tx, err := h.svc.ExecuteSponsored(&req.Authorization, calls, sponsorPK)
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{
+		"tx_hash":       tx.Hash().Hex(),
+		"authorization": auth,
+		"sender":        auth.Signer.Hex(),
+		"warnings":      h.svc.AuthorizationWarnings(auth),
+	}, gas))
+}
+
+// maxRevokeWait limita a espera opcional de /revoke; sem wait_seconds a rota só envia a tx
+const maxRevokeWait = 15 * time.Second
+
+// handleRevoke - revoga a delegação (autorização para 0x0). Responde 202 com o tx_hash;
+// a confirmação fica com GET /tx/{hash} e GET /delegation/{address}, ou com wait_seconds.
+func (h *DelegationHandlers) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	var req RevokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if req.SignerPK == "" && req.Authorization == nil {
+		http.Error(w, "missing signer_pk or authorization", http.StatusBadRequest)
+		return
+	}
+
+	sp, err := h.sponsor(req.Sponsor)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	fmt.Printf("Transaction created: %s\n", tx.Hash().Hex())
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Enviar transação para a rede
-	if err := h.svc.RPC.SendTransaction(tx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), http.StatusInternalServerError)
+	wait := time.Duration(req.WaitSeconds) * time.Second
+	if wait < 0 || wait > maxRevokeWait {
+		http.Error(w, fmt.Sprintf("wait_seconds must be between 0 and %d", int(maxRevokeWait/time.Second)), http.StatusBadRequest)
 		return
 	}
 
-	fmt.Printf("Transaction sent successfully: %s\n", tx.Hash().Hex())
+	// Revogação assinada no cliente (prepare/submit com contract_address zero) ou
+	// assinada aqui com signer_pk
+	var tx *types.Transaction
+	var auth *Authorization
+	if req.Authorization != nil {
+		var status int
+		auth, status, err = h.resolveAuthorization(r.Context(), "", req.Authorization, common.Address{})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		tx, err = h.svc.RevokeWithAuthorization(r.Context(), auth, sp, opts)
+	} else {
+		sk, keyErr := parseKeySigner(req.SignerPK)
+		if keyErr != nil {
+			http.Error(w, fmt.Sprintf("Invalid signer private key: %v", keyErr), http.StatusBadRequest)
+			return
+		}
+		tx, auth, err = h.svc.RevokeDelegation(r.Context(), sk, sp, opts)
+	}
+	if err != nil {
+		if !writeRPCError(w, "Failed to create revocation", err) {
+			http.Error(w, fmt.Sprintf("Failed to create revocation: %v", err), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, map[string]interface{}{
+			"authorization": auth,
+			"authority":     auth.Signer.Hex(),
+		})
+		return
+	}
+
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"tx_hash":       tx.Hash().Hex(),
+		"authorization": auth,
+		"authority":     auth.Signer.Hex(),
+		"revoked":       false,
+	}
 
-	// Retornar hash da transação
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"tx_hash": tx.Hash().Hex(),
-		"sponsor": sponsorAddr.Hex(),
-	})
+
+	// Sem espera (padrão) ou tx ainda pendente: o cliente confirma depois
+	if wait == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	receipt, err := h.svc.WaitMined(r.Context(), tx.Hash(), wait)
+	if err != nil {
+		resp["error"] = err.Error()
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	resp["block_number"] = receipt.BlockNumber.Uint64()
+
+	if err := h.svc.ConfirmRevocation(r.Context(), auth.Signer); err != nil {
+		resp["error"] = err.Error()
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	resp["revoked"] = true
+	json.NewEncoder(w).Encode(resp)
 }
 
 // handleBuildSendETH - Helper para construir call data de sendETH
@@ -472,14 +1278,14 @@ func (h *DelegationHandlers) handleBuildMint(w http.ResponseWriter, r *http.Requ
 
 	builder := &CallDataBuilder{}
 	amount := TokenAmountToWei(req.Amount, 18)
-	tokenAddr := common.HexToAddress(TokenContract)
+	tokenAddr := h.svc.TokenAddress
 	callData := builder.Mint(tokenAddr, common.HexToAddress(req.Recipient), amount)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"call_data":     callData,
 		"function":      "mint",
-		"token_address": TokenContract,
+		"token_address": tokenAddr.Hex(),
 		"recipient":     req.Recipient,
 		"amount":        req.Amount,
 		"amount_wei":    amount.String(),
@@ -505,14 +1311,14 @@ func (h *DelegationHandlers) handleBuildTransfer(w http.ResponseWriter, r *http.
 
 	builder := &CallDataBuilder{}
 	amount := TokenAmountToWei(req.Amount, 18)
-	tokenAddr := common.HexToAddress(TokenContract)
+	tokenAddr := h.svc.TokenAddress
 	callData := builder.Transfer(tokenAddr, common.HexToAddress(req.Recipient), amount)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"call_data":     callData,
 		"function":      "transfer",
-		"token_address": TokenContract,
+		"token_address": tokenAddr.Hex(),
 		"recipient":     req.Recipient,
 		"amount":        req.Amount,
 		"amount_wei":    amount.String(),
@@ -533,15 +1339,16 @@ func (h *DelegationHandlers) handleSponsorETH(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	sk, err := parsePrivateKey(req.SignerPK)
+	opts, err := parseSponsorOptions(req.MaxFeePerGas, req.Simulate)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid signer private key: %v", err), 400)
+		http.Error(w, err.Error(), 400)
 		return
 	}
+	opts.AccessList = req.AccessList
 
-	sp, err := parsePrivateKey(req.SponsorPK)
+	sp, err := h.sponsor(req.Sponsor)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid sponsor private key: %v", err), 400)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -550,9 +1357,9 @@ func (h *DelegationHandlers) handleSponsorETH(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	auth, err := h.svc.SignDelegation(common.HexToAddress(DelegateContract), sk)
+	auth, status, err := h.resolveAuthorization(r.Context(), req.SignerPK, req.Authorization, h.svc.DelegateAddress)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create authorization: %v", err), 500)
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -565,25 +1372,31 @@ func (h *DelegationHandlers) handleSponsorETH(w http.ResponseWriter, r *http.Req
 		Value: val,
 	}
 
-	tx, err := h.svc.ExecuteSponsored(auth, []Call{call}, sp)
+	tx, gas, err := h.svc.ExecuteSponsored(r.Context(), auth, []Call{call}, sp, opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to execute sponsored transaction: %v", err), 500)
+		writeExecuteError(w, err)
+		return
+	}
+
+	// broadcast: false devolve a tx assinada para revisão/envio externo
+	if !shouldBroadcast(req.Broadcast) {
+		h.writePreview(w, tx, withAccessList(map[string]interface{}{"authorization": auth}, gas))
 		return
 	}
 
-	if err := h.svc.RPC.SendTransaction(tx); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to send transaction: %v", err), 500)
+	if err := h.svc.SendTransaction(r.Context(), tx); err != nil {
+		writeSendError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withAccessList(map[string]interface{}{
 		"tx_hash":    tx.Hash().Hex(),
 		"operation":  "sendETH",
 		"amount":     req.Amount,
 		"amount_wei": val.String(),
 		"recipient":  req.Recipient,
-	})
+	}, gas))
 }
 
 // handleSponsorToken - fluxo completo para enviar tokens ERC20 patrocinados
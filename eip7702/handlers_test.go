@@ -0,0 +1,420 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newTestServer sobe as rotas com o sponsor do env registrado como padrão
+func newTestServer(t *testing.T, env *testEnv) *httptest.Server {
+	t.Helper()
+	sponsors := NewSponsorRegistry()
+	if err := sponsors.Add("default", env.sponsor); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewDelegationHandlers(env.svc, sponsors).Routes())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// doJSON faz o request e decodifica a resposta JSON em out (se não for nil)
+func doJSON(t *testing.T, method, url string, body interface{}, out interface{}) int {
+	t.Helper()
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+	}
+	if resp.StatusCode >= 300 {
+		t.Logf("%s %s -> %d: %s", method, url, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return resp.StatusCode
+}
+
+func keyHex(s *KeySigner) string {
+	return common.Bytes2Hex(crypto.FromECDSA(s.key))
+}
+
+func TestSponsorRoutes(t *testing.T) {
+	recipient := common.HexToAddress("0x000000000000000000000000000000000000beef")
+
+	for _, route := range []string{"/sponsor-mint", "/sponsor-transfer", "/sponsor-eth"} {
+		t.Run(route, func(t *testing.T) {
+			env := newTestEnv(t)
+			srv := newTestServer(t, env)
+			authority := newTestSigner(t)
+			env.chain.Fund(authority.Address(), big.NewInt(params.Ether))
+
+			var resp struct {
+				TxHash common.Hash `json:"tx_hash"`
+			}
+			status := doJSON(t, http.MethodPost, srv.URL+route, BasicSponsorRequest{
+				SignerPK:  keyHex(authority),
+				Recipient: recipient.Hex(),
+				Amount:    "1",
+			}, &resp)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d", status)
+			}
+			if _, err := env.chain.TransactionReceipt(context.Background(), resp.TxHash); err != nil {
+				t.Fatalf("tx %s not mined: %v", resp.TxHash, err)
+			}
+			env.requireDelegated(t, authority.Address(), env.svc.DelegateAddress)
+		})
+	}
+}
+
+func TestSponsorRouteRejectsForgedAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+
+	auth, err := env.svc.SignDelegation(context.Background(), env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.Signer = newTestSigner(t).Address()
+
+	status := doJSON(t, http.MethodPost, srv.URL+"/sponsor", SponsorRequest{
+		Authorization: *auth,
+		Calls:         []CallData{{To: authority.Address().Hex(), Data: "0x", Value: "0"}},
+	}, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if nonce, _ := env.chain.NonceAt(context.Background(), env.sponsor.Address()); nonce != 0 {
+		t.Fatalf("sponsor sent %d txs", nonce)
+	}
+}
+
+func TestSponsorRouteRejectsUntrustedContract(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+
+	// Assinatura válida, mas para um contrato fora da lista
+	auth, err := env.svc.signAuthorization(context.Background(), common.Address{0xde, 0xad}, authority, DelegationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := doJSON(t, http.MethodPost, srv.URL+"/sponsor", SponsorRequest{
+		Authorization: *auth,
+		Calls:         []CallData{{To: authority.Address().Hex(), Data: "0x", Value: "0"}},
+	}, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if nonce, _ := env.chain.PendingNonceAt(context.Background(), env.sponsor.Address()); nonce != 0 {
+		t.Fatalf("sponsor sent %d txs", nonce)
+	}
+}
+
+func TestSponsorBatchRoute(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	ctx := context.Background()
+
+	first, second := newTestSigner(t), newTestSigner(t)
+	var auths []*Authorization
+	for _, authority := range []*KeySigner{first, second} {
+		auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auths = append(auths, auth)
+	}
+
+	var resp struct {
+		TxHash   common.Hash                `json:"tx_hash"`
+		Included int                        `json:"included"`
+		Results  []BatchAuthorizationResult `json:"results"`
+	}
+	if status := doJSON(t, http.MethodPost, srv.URL+"/sponsor-batch", BatchSponsorRequest{Authorizations: auths}, &resp); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if resp.Included != 2 {
+		t.Fatalf("included = %d, want 2 (%+v)", resp.Included, resp.Results)
+	}
+	env.requireDelegated(t, first.Address(), env.svc.DelegateAddress)
+	env.requireDelegated(t, second.Address(), env.svc.DelegateAddress)
+
+	// Reenviar o mesmo lote não tem nenhuma autorização utilizável
+	if status := doJSON(t, http.MethodPost, srv.URL+"/sponsor-batch", BatchSponsorRequest{Authorizations: auths}, nil); status != http.StatusUnprocessableEntity {
+		t.Fatalf("replayed batch status = %d, want 422", status)
+	}
+}
+
+func TestAuthorizeAndDelegationStatusRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+
+	var authResp struct {
+		Authorization   Authorization `json:"authorization"`
+		AuthorizationID common.Hash   `json:"authorization_id"`
+	}
+	status := doJSON(t, http.MethodPost, srv.URL+"/authorize", AuthorizeRequest{
+		ContractAddress: env.svc.DelegateAddress.Hex(),
+		SignerPK:        keyHex(authority),
+	}, &authResp)
+	if status != http.StatusOK {
+		t.Fatalf("authorize status = %d", status)
+	}
+	if authResp.Authorization.Signer != authority.Address() || authResp.AuthorizationID != AuthorizationID(&authResp.Authorization) {
+		t.Fatalf("authorize response = %+v", authResp)
+	}
+
+	var rec AuthorizationRecord
+	if status := doJSON(t, http.MethodGet, srv.URL+"/authorizations/"+authResp.AuthorizationID.Hex(), nil, &rec); status != http.StatusOK {
+		t.Fatalf("authorization status = %d", status)
+	}
+	if rec.State != AuthorizationIssued {
+		t.Fatalf("state = %s, want issued", rec.State)
+	}
+
+	// Contrato desconhecido é recusado
+	status = doJSON(t, http.MethodPost, srv.URL+"/authorize", AuthorizeRequest{
+		ContractAddress: "0x000000000000000000000000000000000000dead",
+		SignerPK:        keyHex(authority),
+	}, nil)
+	if status == http.StatusOK {
+		t.Fatal("authorization for an unknown contract was signed")
+	}
+
+	var info DelegationInfo
+	if status := doJSON(t, http.MethodGet, srv.URL+"/delegation/"+authority.Address().Hex(), nil, &info); status != http.StatusOK {
+		t.Fatalf("delegation status = %d", status)
+	}
+	if info.Delegated {
+		t.Fatalf("fresh EOA reported as delegated: %+v", info)
+	}
+}
+
+func TestSelfExecuteAndTxStatusRoutes(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+	env.chain.Fund(authority.Address(), big.NewInt(params.Ether))
+
+	call := env.mintCall(authority.Address())
+	var resp struct {
+		TxHash common.Hash `json:"tx_hash"`
+	}
+	status := doJSON(t, http.MethodPost, srv.URL+"/self-execute", SelfExecuteRequest{
+		ContractAddress: env.svc.DelegateAddress.Hex(),
+		SignerPK:        keyHex(authority),
+		Calls:           []CallData{{To: call.To.Hex(), Data: common.Bytes2Hex(call.Data), Value: "0"}},
+	}, &resp)
+	if status != http.StatusOK {
+		t.Fatalf("self-execute status = %d", status)
+	}
+	env.requireDelegated(t, authority.Address(), env.svc.DelegateAddress)
+
+	if err := env.svc.PollTransactions(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var tracked TrackedTx
+	if status := doJSON(t, http.MethodGet, srv.URL+"/tx/"+resp.TxHash.Hex(), nil, &tracked); status != http.StatusOK {
+		t.Fatalf("tx status = %d", status)
+	}
+	if tracked.From != authority.Address() || tracked.Status != TxMined || tracked.BlockNumber == nil {
+		t.Fatalf("tracked tx = %+v", tracked)
+	}
+
+	if status := doJSON(t, http.MethodGet, srv.URL+"/tx/"+common.Hash{1}.Hex(), nil, nil); status != http.StatusNotFound {
+		t.Fatalf("unknown tx status = %d, want 404", status)
+	}
+}
+
+func TestRevokeRoute(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+
+	// Padrão: responde 202 logo após o envio
+	authority := newTestSigner(t)
+	env.sponsorDelegation(t, authority)
+	var resp struct {
+		TxHash  common.Hash `json:"tx_hash"`
+		Revoked bool        `json:"revoked"`
+	}
+	if status := doJSON(t, http.MethodPost, srv.URL+"/revoke", RevokeRequest{SignerPK: keyHex(authority)}, &resp); status != http.StatusAccepted {
+		t.Fatalf("revoke status = %d, want 202", status)
+	}
+	if resp.Revoked || resp.TxHash == (common.Hash{}) {
+		t.Fatalf("revoke response = %+v", resp)
+	}
+	if status := doJSON(t, http.MethodGet, srv.URL+"/tx/"+resp.TxHash.Hex(), nil, nil); status != http.StatusOK {
+		t.Fatalf("tx status = %d", status)
+	}
+	if err := env.svc.ConfirmRevocation(context.Background(), authority.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait_seconds espera a mineração e confirma
+	authority = newTestSigner(t)
+	env.sponsorDelegation(t, authority)
+	if status := doJSON(t, http.MethodPost, srv.URL+"/revoke", RevokeRequest{SignerPK: keyHex(authority), WaitSeconds: 5}, &resp); status != http.StatusOK {
+		t.Fatalf("revoke with wait status = %d, want 200", status)
+	}
+	if !resp.Revoked {
+		t.Fatalf("revoke with wait response = %+v", resp)
+	}
+
+	if status := doJSON(t, http.MethodPost, srv.URL+"/revoke", RevokeRequest{SignerPK: keyHex(authority), WaitSeconds: 600}, nil); status != http.StatusBadRequest {
+		t.Fatalf("long wait status = %d, want 400", status)
+	}
+}
+
+func TestRevokeRouteWithClientSignedAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+	env.sponsorDelegation(t, authority)
+
+	// prepare/submit com contract_address zero: a chave não passa pela API
+	var prepared PreparedAuthorization
+	if status := doJSON(t, http.MethodPost, srv.URL+"/authorize/prepare", PrepareAuthorizationRequest{
+		Authority:       authority.Address().Hex(),
+		ContractAddress: common.Address{}.Hex(),
+	}, &prepared); status != http.StatusOK {
+		t.Fatalf("prepare status = %d", status)
+	}
+	sig, err := authority.SignHash(context.Background(), prepared.SigningHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var submitted struct {
+		Authorization *Authorization `json:"authorization"`
+	}
+	if status := doJSON(t, http.MethodPost, srv.URL+"/authorize/submit", SubmitAuthorizationRequest{
+		Authority:       authority.Address().Hex(),
+		ContractAddress: common.Address{}.Hex(),
+		ChainID:         prepared.ChainID,
+		Nonce:           prepared.Nonce,
+		YParity:         sig[64],
+		R:               hexutil.Encode(sig[:32]),
+		S:               hexutil.Encode(sig[32:64]),
+	}, &submitted); status != http.StatusOK {
+		t.Fatalf("submit status = %d", status)
+	}
+
+	// A revogação não serve para as rotas de delegação
+	if status := doJSON(t, http.MethodPost, srv.URL+"/sponsor-mint", BasicSponsorRequest{
+		Authorization: submitted.Authorization,
+		Recipient:     authority.Address().Hex(),
+		Amount:        "1",
+	}, nil); status != http.StatusBadRequest {
+		t.Fatalf("sponsor with a revocation status = %d, want 400", status)
+	}
+
+	var resp struct {
+		Revoked bool `json:"revoked"`
+	}
+	if status := doJSON(t, http.MethodPost, srv.URL+"/revoke", RevokeRequest{Authorization: submitted.Authorization, WaitSeconds: 5}, &resp); status != http.StatusOK {
+		t.Fatalf("revoke status = %d, want 200", status)
+	}
+	if !resp.Revoked {
+		t.Fatalf("revoke response = %+v", resp)
+	}
+	if err := env.svc.ConfirmRevocation(context.Background(), authority.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := doJSON(t, http.MethodPost, srv.URL+"/revoke", RevokeRequest{}, nil); status != http.StatusBadRequest {
+		t.Fatalf("revoke without key or authorization status = %d, want 400", status)
+	}
+}
+
+func TestPreviewDoesNotBroadcast(t *testing.T) {
+	env := newTestEnv(t)
+	srv := newTestServer(t, env)
+	authority := newTestSigner(t)
+	broadcast := false
+
+	var resp struct {
+		Broadcast   bool      `json:"broadcast"`
+		Transaction TxPreview `json:"transaction"`
+	}
+	status := doJSON(t, http.MethodPost, srv.URL+"/sponsor-mint", BasicSponsorRequest{
+		SignerPK:  keyHex(authority),
+		Recipient: authority.Address().Hex(),
+		Amount:    "1",
+		Broadcast: &broadcast,
+	}, &resp)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if resp.Broadcast || len(resp.Transaction.RawTransaction) == 0 || resp.Transaction.RawTransaction[0] != 0x04 {
+		t.Fatalf("preview = %+v", resp)
+	}
+	ctx := context.Background()
+	if nonce, _ := env.chain.PendingNonceAt(ctx, env.sponsor.Address()); nonce != 0 {
+		t.Fatalf("preview was broadcast: sponsor pending nonce %d", nonce)
+	}
+
+	// A raw tx pode ser enviada por fora: nonce e autorização continuam presos a ela
+	hash := resp.Transaction.Hash
+	if st := env.svc.Nonces.Status(env.sponsor.Address()); st.Pending[resp.Transaction.Nonce] != hash {
+		t.Fatalf("preview nonce released: %+v", st)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(resp.Transaction.AuthorizationList[0]))
+	if err != nil || rec.TxHash == nil || *rec.TxHash != hash {
+		t.Fatalf("authorization record after preview = %+v, err %v", rec, err)
+	}
+
+	var raw types.Transaction
+	if err := raw.UnmarshalBinary(resp.Transaction.RawTransaction); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.chain.SendTransaction(ctx, &raw); err != nil {
+		t.Fatalf("external broadcast of the preview: %v", err)
+	}
+	other := newTestSigner(t)
+	var sent struct {
+		TxHash common.Hash `json:"tx_hash"`
+	}
+	if status := doJSON(t, http.MethodPost, srv.URL+"/sponsor-mint", BasicSponsorRequest{
+		SignerPK:  keyHex(other),
+		Recipient: other.Address().Hex(),
+		Amount:    "1",
+	}, &sent); status != http.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if tx, _, err := env.chain.TransactionByHash(ctx, sent.TxHash); err != nil || tx.Nonce() == resp.Transaction.Nonce {
+		t.Fatalf("next request reused the preview nonce: %v", err)
+	}
+}
@@ -0,0 +1,392 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultMaxBlockLag é quantos blocos um endpoint pode ficar atrás do melhor antes
+// de ser considerado fora do ar
+const DefaultMaxBlockLag uint64 = 5
+
+// ErrNoQuorum indica que endpoints suficientes não concordaram numa leitura
+var ErrNoQuorum = errors.New("rpc endpoints did not reach quorum")
+
+// RPCEndpoint é um node usado pelo MultiRPCClient; Name aparece em erros e no
+// status de saúde (não use a URL, que costuma carregar a API key)
+type RPCEndpoint struct {
+	Name   string
+	Client EthClient
+}
+
+// EndpointHealth é o último estado conhecido de um endpoint
+type EndpointHealth struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Head      uint64    `json:"head,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// MultiRPCClient implementa EthClient sobre vários nodes:
+//   - leituras vão para o primeiro endpoint saudável, passando ao próximo em falha de transporte
+//   - SendTransaction é enviada a todos os endpoints e basta um aceitar
+//   - com Quorum > 1, ChainID, NonceAt, BalanceAt e CodeAt exigem Quorum respostas iguais
+//
+// Erros respondidos pelo node (revert, nonce too low...) não trocam de endpoint: outro
+// node daria a mesma resposta. A exceção é not found em TransactionByHash e
+// TransactionReceipt: a tx pode ainda não ter chegado ao mempool ou ao head daquele node.
+type MultiRPCClient struct {
+	// MaxBlockLag marca como fora do ar o endpoint atrasado em relação ao melhor head
+	MaxBlockLag uint64
+
+	endpoints []*endpointState
+	quorum    int
+}
+
+type endpointState struct {
+	RPCEndpoint
+	mu     sync.RWMutex
+	health EndpointHealth
+}
+
+func NewMultiRPCClient(endpoints []RPCEndpoint, quorum int) (*MultiRPCClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one rpc endpoint is required")
+	}
+	if quorum < 1 {
+		quorum = 1
+	}
+	if quorum > len(endpoints) {
+		return nil, fmt.Errorf("read quorum %d exceeds the %d configured endpoints", quorum, len(endpoints))
+	}
+
+	m := &MultiRPCClient{MaxBlockLag: DefaultMaxBlockLag, quorum: quorum}
+	for _, ep := range endpoints {
+		// Todos começam saudáveis até o primeiro health check ou falha
+		m.endpoints = append(m.endpoints, &endpointState{
+			RPCEndpoint: ep,
+			health:      EndpointHealth{Name: ep.Name, Healthy: true},
+		})
+	}
+	return m, nil
+}
+
+// Health devolve o estado de cada endpoint, na ordem configurada
+func (m *MultiRPCClient) Health() []EndpointHealth {
+	out := make([]EndpointHealth, len(m.endpoints))
+	for i, ep := range m.endpoints {
+		ep.mu.RLock()
+		out[i] = ep.health
+		ep.mu.RUnlock()
+	}
+	return out
+}
+
+// CheckHealth consulta o head de todos os endpoints; falha ou atraso maior que
+// MaxBlockLag em relação ao melhor head tira o endpoint da rotação de leituras
+func (m *MultiRPCClient) CheckHealth(ctx context.Context) []EndpointHealth {
+	heads := make([]uint64, len(m.endpoints))
+	errs := make([]error, len(m.endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range m.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			heads[i], errs[i] = ep.Client.BlockNumber(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var best uint64
+	for i := range heads {
+		if errs[i] == nil && heads[i] > best {
+			best = heads[i]
+		}
+	}
+
+	now := time.Now()
+	for i, ep := range m.endpoints {
+		health := EndpointHealth{Name: ep.Name, Healthy: true, Head: heads[i], CheckedAt: now}
+		switch {
+		case errs[i] != nil:
+			health = EndpointHealth{Name: ep.Name, Error: errs[i].Error(), CheckedAt: now}
+		case heads[i]+m.MaxBlockLag < best:
+			health.Healthy = false
+			health.Error = fmt.Sprintf("lagging %d blocks behind", best-heads[i])
+		}
+		ep.mu.Lock()
+		ep.health = health
+		ep.mu.Unlock()
+	}
+	return m.Health()
+}
+
+// healthy indica se o endpoint está na rotação
+func (ep *endpointState) healthy() bool {
+	ep.mu.RLock()
+	defer ep.mu.RUnlock()
+	return ep.health.Healthy
+}
+
+// markFailed tira o endpoint da rotação até o próximo health check
+func (ep *endpointState) markFailed(err error) {
+	ep.mu.Lock()
+	ep.health.Healthy = false
+	ep.health.Error = err.Error()
+	ep.health.CheckedAt = time.Now()
+	ep.mu.Unlock()
+}
+
+// ordered devolve os saudáveis primeiro; os demais ficam como último recurso
+func (m *MultiRPCClient) ordered() []*endpointState {
+	out := make([]*endpointState, 0, len(m.endpoints))
+	var down []*endpointState
+	for _, ep := range m.endpoints {
+		if ep.healthy() {
+			out = append(out, ep)
+		} else {
+			down = append(down, ep)
+		}
+	}
+	return append(out, down...)
+}
+
+// failover chama os endpoints em ordem até um responder
+func failover[T any](ctx context.Context, m *MultiRPCClient, call func(EthClient) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for _, ep := range m.ordered() {
+		v, err := call(ep.Client)
+		if err == nil || nodeAnswered(err) {
+			return v, err
+		}
+		// Request cancelado por quem chamou não é culpa do endpoint
+		if ctx.Err() != nil {
+			return zero, err
+		}
+		ep.markFailed(err)
+		errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+	}
+	return zero, fmt.Errorf("all rpc endpoints failed: %w", errors.Join(errs...))
+}
+
+// failoverLookup é o failover das buscas por hash: NotFound passa ao próximo endpoint.
+// Só devolve NotFound se nenhum endpoint tiver a tx e todos tiverem respondido; com
+// falha de transporte em algum, a ausência não é conclusiva.
+func failoverLookup[T any](ctx context.Context, m *MultiRPCClient, call func(EthClient) (T, error)) (T, error) {
+	var zero T
+	var errs []error
+	for _, ep := range m.ordered() {
+		v, err := call(ep.Client)
+		if err == nil {
+			return v, nil
+		}
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if nodeAnswered(err) {
+			return v, err
+		}
+		if ctx.Err() != nil {
+			return zero, err
+		}
+		ep.markFailed(err)
+		errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+	}
+	if len(errs) == 0 {
+		return zero, ethereum.NotFound
+	}
+	return zero, fmt.Errorf("not found on the answering rpc endpoints, others failed: %w", errors.Join(errs...))
+}
+
+// quorumRead consulta todos os endpoints em paralelo e devolve o valor assim que
+// m.quorum deles retornarem o mesmo (key identifica respostas iguais), sem esperar
+// os mais lentos
+func quorumRead[T any](ctx context.Context, m *MultiRPCClient, call func(EthClient) (T, error), key func(T) string) (T, error) {
+	if m.quorum <= 1 {
+		return failover(ctx, m, call)
+	}
+
+	type answer struct {
+		ep    *endpointState
+		value T
+		err   error
+	}
+	// Buffer para todos: quem responder depois do quorum não fica bloqueado
+	answers := make(chan answer, len(m.endpoints))
+	for _, ep := range m.endpoints {
+		go func() {
+			v, err := call(ep.Client)
+			if err != nil && !nodeAnswered(err) && ctx.Err() == nil {
+				ep.markFailed(err)
+			}
+			answers <- answer{ep, v, err}
+		}()
+	}
+
+	votes := make(map[string]int)
+	best := 0
+	var seen []string
+	var errs []error
+	for left := len(m.endpoints); left > 0; left-- {
+		var a answer
+		select {
+		case a = <-answers:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if a.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.ep.Name, a.err))
+		} else {
+			k := key(a.value)
+			votes[k]++
+			if votes[k] >= m.quorum {
+				return a.value, nil
+			}
+			best = max(best, votes[k])
+			seen = append(seen, fmt.Sprintf("%s=%s", a.ep.Name, k))
+		}
+		// Nem as respostas que faltam alcançam o quorum
+		if best+left-1 < m.quorum {
+			break
+		}
+	}
+
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	detail := strings.Join(seen, ", ")
+	if len(errs) > 0 {
+		detail = strings.TrimPrefix(detail+"; "+errors.Join(errs...).Error(), "; ")
+	}
+	return zero, fmt.Errorf("%w (%d required): %s", ErrNoQuorum, m.quorum, detail)
+}
+
+func bigKey(v *big.Int) string { return v.String() }
+func uintKey(v uint64) string  { return fmt.Sprint(v) }
+func codeKey(v []byte) string  { return common.Bytes2Hex(v) }
+
+func (m *MultiRPCClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return quorumRead(ctx, m, func(c EthClient) (*big.Int, error) { return c.ChainID(ctx) }, bigKey)
+}
+
+func (m *MultiRPCClient) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	return quorumRead(ctx, m, func(c EthClient) (uint64, error) { return c.NonceAt(ctx, from) }, uintKey)
+}
+
+func (m *MultiRPCClient) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return quorumRead(ctx, m, func(c EthClient) (*big.Int, error) { return c.BalanceAt(ctx, account) }, bigKey)
+}
+
+func (m *MultiRPCClient) CodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return quorumRead(ctx, m, func(c EthClient) ([]byte, error) { return c.CodeAt(ctx, account) }, codeKey)
+}
+
+// PendingNonceAt não usa quorum: o mempool de cada provedor pode estar em um ponto diferente
+func (m *MultiRPCClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return failover(ctx, m, func(c EthClient) (uint64, error) { return c.PendingNonceAt(ctx, account) })
+}
+
+func (m *MultiRPCClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return failover(ctx, m, func(c EthClient) (*big.Int, error) { return c.SuggestGasTipCap(ctx) })
+}
+
+func (m *MultiRPCClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return failoverLookup(ctx, m, func(c EthClient) (*types.Receipt, error) { return c.TransactionReceipt(ctx, hash) })
+}
+
+func (m *MultiRPCClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return failover(ctx, m, func(c EthClient) (uint64, error) { return c.EstimateGas(ctx, msg) })
+}
+
+func (m *MultiRPCClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return failover(ctx, m, func(c EthClient) (*types.Header, error) { return c.HeaderByNumber(ctx, number) })
+}
+
+func (m *MultiRPCClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return failover(ctx, m, func(c EthClient) (*ethereum.FeeHistory, error) {
+		return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (m *MultiRPCClient) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	return failover(ctx, m, func(c EthClient) ([]byte, error) { return c.CallContract(ctx, msg, codeOverrides) })
+}
+
+func (m *MultiRPCClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return failover(ctx, m, func(c EthClient) (uint64, error) { return c.BlockNumber(ctx) })
+}
+
+func (m *MultiRPCClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	r, err := failoverLookup(ctx, m, func(c EthClient) (result, error) {
+		tx, pending, err := c.TransactionByHash(ctx, hash)
+		return result{tx, pending}, err
+	})
+	return r.tx, r.pending, err
+}
+
+func (m *MultiRPCClient) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	type result struct {
+		list    types.AccessList
+		gasUsed uint64
+	}
+	r, err := failover(ctx, m, func(c EthClient) (result, error) {
+		list, gasUsed, err := c.CreateAccessList(ctx, msg)
+		return result{list, gasUsed}, err
+	})
+	return r.list, r.gasUsed, err
+}
+
+// SendTransaction envia a tx a todos os endpoints (saudáveis ou não) e retorna no
+// primeiro aceite; "already known" conta como aceite. Se todos recusarem, devolve a
+// recusa de um node (ex.: nonce too low) antes de falhas de transporte.
+func (m *MultiRPCClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	type sent struct {
+		ep  *endpointState
+		err error
+	}
+	results := make(chan sent, len(m.endpoints))
+	for _, ep := range m.endpoints {
+		go func() {
+			results <- sent{ep, ep.Client.SendTransaction(ctx, tx)}
+		}()
+	}
+
+	var rejected error
+	var errs []error
+	for range m.endpoints {
+		r := <-results
+		if r.err == nil || isAlreadyKnown(r.err) {
+			return nil
+		}
+		if nodeAnswered(r.err) {
+			if rejected == nil {
+				rejected = r.err
+			}
+		} else if ctx.Err() == nil {
+			r.ep.markFailed(r.err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", r.ep.Name, r.err))
+	}
+	if rejected != nil {
+		return rejected
+	}
+	return fmt.Errorf("broadcast failed on all rpc endpoints: %w", errors.Join(errs...))
+}
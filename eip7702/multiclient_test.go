@@ -0,0 +1,105 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/omnes/eip7702/eip7702/fakechain"
+)
+
+// downClient simula um endpoint fora do ar nas buscas por hash
+type downClient struct {
+	*fakechain.Chain
+}
+
+func (downClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (downClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return nil, errors.New("connection refused")
+}
+
+// stuckNonces segura NonceAt até unblock ser fechado
+type stuckNonces struct {
+	*fakechain.Chain
+	unblock chan struct{}
+}
+
+func (s *stuckNonces) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	<-s.unblock
+	return s.Chain.NonceAt(ctx, from)
+}
+
+func TestMultiRPCClientLookupTriesOtherEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	tx, _ := env.sponsorDelegation(t, newTestSigner(t))
+
+	// O primeiro endpoint ainda não viu a tx
+	behind := fakechain.New(testChainID)
+	m, err := NewMultiRPCClient([]RPCEndpoint{{Name: "behind", Client: behind}, {Name: "main", Client: env.chain}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, err := m.TransactionByHash(ctx, tx.Hash()); err != nil || got.Hash() != tx.Hash() {
+		t.Fatalf("TransactionByHash = %v, err %v", got, err)
+	}
+	if receipt, err := m.TransactionReceipt(ctx, tx.Hash()); err != nil || receipt.TxHash != tx.Hash() {
+		t.Fatalf("TransactionReceipt = %v, err %v", receipt, err)
+	}
+
+	// Ausente em todos: NotFound
+	if _, err := m.TransactionReceipt(ctx, common.Hash{1}); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+
+	// Ausente num e o outro fora do ar: não é conclusivo
+	m, err = NewMultiRPCClient([]RPCEndpoint{{Name: "behind", Client: behind}, {Name: "down", Client: downClient{env.chain}}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.TransactionByHash(ctx, tx.Hash()); err == nil || errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("err = %v, want a non-NotFound failure", err)
+	}
+}
+
+func TestMultiRPCClientQuorumReturnsEarly(t *testing.T) {
+	chain := fakechain.New(testChainID)
+	addr := common.Address{1}
+	chain.SetNonce(addr, 7)
+	stuck := &stuckNonces{Chain: chain, unblock: make(chan struct{})}
+	defer close(stuck.unblock)
+
+	m, err := NewMultiRPCClient([]RPCEndpoint{
+		{Name: "a", Client: chain},
+		{Name: "stuck", Client: stuck},
+		{Name: "b", Client: chain},
+	}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		nonce, err := m.NonceAt(context.Background(), addr)
+		if err == nil && nonce != 7 {
+			err = fmt.Errorf("nonce = %d, want 7", nonce)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("quorum read waited for the stuck endpoint")
+	}
+}
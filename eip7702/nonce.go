@@ -0,0 +1,235 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nonceResyncInterval é de quanto em quanto tempo uma conta ociosa é ressincronizada com o node
+const nonceResyncInterval = 30 * time.Second
+
+// externalNonceHold é por quanto tempo um nonce entregue para envio externo (broadcast: false)
+// fica reservado, mesmo que o node ainda não conheça a tx
+const externalNonceHold = 10 * time.Minute
+
+// NonceManager distribui os nonces dos sponsors em sequência, sem consultar o node
+// a cada request. Requests concorrentes recebem nonces distintos; nonces não enviados
+// voltam para a fila e gaps (tx descartada pelo node) são detectados no resync.
+// Cada conta tem o próprio lock: o resync de um sponsor não trava os outros.
+type NonceManager struct {
+	rpc      EthClient
+	mu       sync.Mutex // protege só o mapa de contas
+	accounts map[common.Address]*accountNonces
+}
+
+// accountNonces é o estado local de uma conta
+type accountNonces struct {
+	mu       sync.Mutex             // serializa a conta, inclusive durante a consulta ao node no resync
+	next     uint64                 // próximo nonce nunca entregue
+	reserved map[uint64]bool        // entregues, ainda não enviados
+	pending  map[uint64]common.Hash // enviados, ainda não vistos como usados pelo node
+	external map[uint64]time.Time   // entregues para envio externo: não viram gap antes do prazo
+	free     []uint64               // devolvidos ou gaps: reutilizados antes de next
+	synced   time.Time
+}
+
+// NonceStatus é a visão do estado local de uma conta
+type NonceStatus struct {
+	Next     uint64                 `json:"next"`
+	Reserved []uint64               `json:"reserved"`
+	Pending  map[uint64]common.Hash `json:"pending"`
+	External map[uint64]time.Time   `json:"external,omitempty"`
+	Gaps     []uint64               `json:"gaps"`
+	SyncedAt time.Time              `json:"synced_at"`
+}
+
+func NewNonceManager(rpc EthClient) *NonceManager {
+	return &NonceManager{rpc: rpc, accounts: make(map[common.Address]*accountNonces)}
+}
+
+// Reserve entrega o próximo nonce da conta. Quem reserva deve chamar Commit
+// (tx enviada) ou Release (tx descartada).
+func (m *NonceManager) Reserve(ctx context.Context, addr common.Address) (uint64, error) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	// Conta ociosa há muito tempo (ou nunca sincronizada): alinhar com o node
+	if len(acc.reserved) == 0 && time.Since(acc.synced) > nonceResyncInterval {
+		if err := m.resync(ctx, addr, acc); err != nil {
+			return 0, err
+		}
+	}
+
+	var nonce uint64
+	if len(acc.free) > 0 {
+		nonce, acc.free = acc.free[0], acc.free[1:]
+	} else {
+		nonce = acc.next
+		acc.next++
+	}
+	acc.reserved[nonce] = true
+	return nonce, nil
+}
+
+// Commit marca o nonce como enviado na tx informada
+func (m *NonceManager) Commit(addr common.Address, nonce uint64, hash common.Hash) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	delete(acc.reserved, nonce)
+	acc.pending[nonce] = hash
+}
+
+// CommitExternal marca o nonce como entregue na tx informada para outra infraestrutura
+// enviar. Até until o resync não o trata como gap, mesmo sem o node conhecer a tx.
+func (m *NonceManager) CommitExternal(addr common.Address, nonce uint64, hash common.Hash, until time.Time) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	delete(acc.reserved, nonce)
+	acc.pending[nonce] = hash
+	acc.external[nonce] = until
+}
+
+// Release devolve um nonce reservado que não chegou a ser enviado
+func (m *NonceManager) Release(addr common.Address, nonce uint64) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	if !acc.reserved[nonce] {
+		return
+	}
+	delete(acc.reserved, nonce)
+	acc.addFree(nonce)
+}
+
+// Resync realinha a conta com o nonce pending do node e devolve os gaps encontrados
+func (m *NonceManager) Resync(ctx context.Context, addr common.Address) ([]uint64, error) {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	if err := m.resync(ctx, addr, acc); err != nil {
+		return nil, err
+	}
+	return append([]uint64(nil), acc.free...), nil
+}
+
+// Status devolve uma cópia do estado local da conta
+func (m *NonceManager) Status(addr common.Address) NonceStatus {
+	acc := m.account(addr)
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	st := NonceStatus{
+		Next:     acc.next,
+		Reserved: make([]uint64, 0, len(acc.reserved)),
+		Pending:  make(map[uint64]common.Hash, len(acc.pending)),
+		Gaps:     append([]uint64{}, acc.free...),
+		SyncedAt: acc.synced,
+	}
+	for n := range acc.reserved {
+		st.Reserved = append(st.Reserved, n)
+	}
+	sort.Slice(st.Reserved, func(i, j int) bool { return st.Reserved[i] < st.Reserved[j] })
+	for n, h := range acc.pending {
+		st.Pending[n] = h
+	}
+	if len(acc.external) > 0 {
+		st.External = make(map[uint64]time.Time, len(acc.external))
+		for n, until := range acc.external {
+			st.External[n] = until
+		}
+	}
+	return st
+}
+
+// account devolve (criando) o estado da conta; o lock do mapa não é mantido
+func (m *NonceManager) account(addr common.Address) *accountNonces {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &accountNonces{
+			reserved: make(map[uint64]bool),
+			pending:  make(map[uint64]common.Hash),
+			external: make(map[uint64]time.Time),
+		}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// resync compara o estado local com o nonce pending do node (chamado com acc.mu travado):
+//   - nonces abaixo do pending do node já foram usados: saem de pending/free
+//   - node à frente (tx enviada por fora do serviço): pula para o nonce do node
+//   - node atrás com o nonce dele marcado como enviado: a tx foi descartada, é um gap
+//     (exceto se entregue para envio externo e ainda dentro do prazo)
+func (m *NonceManager) resync(ctx context.Context, addr common.Address, acc *accountNonces) error {
+	if m.rpc == nil {
+		return errors.New("nonce manager has no RPC client")
+	}
+	nodeNonce, err := m.rpc.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce for %s: %w", addr.Hex(), err)
+	}
+
+	for n := range acc.pending {
+		if n < nodeNonce {
+			delete(acc.pending, n)
+		}
+	}
+	now := time.Now()
+	for n, until := range acc.external {
+		if n < nodeNonce || now.After(until) {
+			delete(acc.external, n)
+		}
+	}
+	free := acc.free[:0]
+	for _, n := range acc.free {
+		if n >= nodeNonce {
+			free = append(free, n)
+		}
+	}
+	acc.free = free
+
+	switch {
+	case nodeNonce > acc.next:
+		acc.next = nodeNonce
+	case nodeNonce < acc.next && !acc.reserved[nodeNonce] && acc.external[nodeNonce].IsZero():
+		// O node não conhece o nonce que enviamos (ou nunca enviamos): preencher o gap
+		delete(acc.pending, nodeNonce)
+		acc.addFree(nodeNonce)
+	}
+
+	acc.synced = now
+	return nil
+}
+
+// addFree devolve o nonce para a fila, encolhendo next quando ele é o último
+func (acc *accountNonces) addFree(nonce uint64) {
+	for _, n := range acc.free {
+		if n == nonce {
+			return
+		}
+	}
+	acc.free = append(acc.free, nonce)
+	sort.Slice(acc.free, func(i, j int) bool { return acc.free[i] < acc.free[j] })
+
+	// Nonces livres no topo não são gaps: basta recuar next
+	for len(acc.free) > 0 && acc.next > 0 && acc.free[len(acc.free)-1] == acc.next-1 {
+		acc.free = acc.free[:len(acc.free)-1]
+		acc.next--
+	}
+}
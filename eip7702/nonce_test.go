@@ -0,0 +1,119 @@
+package eip7702
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/omnes/eip7702/eip7702/fakechain"
+)
+
+// blockingPendingNonce segura PendingNonceAt de uma conta até unblock ser fechado
+type blockingPendingNonce struct {
+	*fakechain.Chain
+	slow    common.Address
+	unblock chan struct{}
+}
+
+func (b *blockingPendingNonce) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	if account == b.slow {
+		<-b.unblock
+	}
+	return b.Chain.PendingNonceAt(ctx, account)
+}
+
+func TestNonceManagerLocksPerAccount(t *testing.T) {
+	chain := fakechain.New(big.NewInt(1337))
+	slow, fast := common.Address{1}, common.Address{2}
+	rpc := &blockingPendingNonce{Chain: chain, slow: slow, unblock: make(chan struct{})}
+	m := NewNonceManager(rpc)
+	ctx := context.Background()
+
+	slowDone := make(chan uint64)
+	go func() {
+		n, err := m.Reserve(ctx, slow)
+		if err != nil {
+			t.Error(err)
+		}
+		slowDone <- n
+	}()
+
+	// O resync da conta lenta não pode travar as outras
+	fastDone := make(chan error)
+	go func() {
+		_, err := m.Reserve(ctx, fast)
+		fastDone <- err
+	}()
+	select {
+	case err := <-fastDone:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reserve for another account blocked behind a slow resync")
+	}
+
+	close(rpc.unblock)
+	if n := <-slowDone; n != 0 {
+		t.Fatalf("slow account nonce = %d, want 0", n)
+	}
+}
+
+func TestNonceManagerConcurrentReserves(t *testing.T) {
+	m := NewNonceManager(fakechain.New(big.NewInt(1337)))
+	addr := common.Address{1}
+	ctx := context.Background()
+
+	const n = 50
+	got := make(chan uint64, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			nonce, err := m.Reserve(ctx, addr)
+			if err != nil {
+				t.Error(err)
+			}
+			got <- nonce
+		}()
+	}
+	seen := make(map[uint64]bool, n)
+	for i := 0; i < n; i++ {
+		nonce := <-got
+		if seen[nonce] {
+			t.Fatalf("nonce %d handed out twice", nonce)
+		}
+		seen[nonce] = true
+	}
+	if st := m.Status(addr); st.Next != n || len(st.Reserved) != n {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestNonceManagerHoldsExternalNonce(t *testing.T) {
+	m := NewNonceManager(fakechain.New(big.NewInt(1337)))
+	addr := common.Address{1}
+	ctx := context.Background()
+
+	held, err := m.Reserve(ctx, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.CommitExternal(addr, held, common.Hash{1}, time.Now().Add(time.Minute))
+
+	// O node ainda não conhece a tx: dentro do prazo o nonce não é gap
+	if gaps, err := m.Resync(ctx, addr); err != nil || len(gaps) != 0 {
+		t.Fatalf("gaps = %v, err %v", gaps, err)
+	}
+	if next, _ := m.Reserve(ctx, addr); next != held+1 {
+		t.Fatalf("next nonce = %d, want %d", next, held+1)
+	}
+
+	// Prazo vencido sem envio: o nonce volta para a fila
+	other := common.Address{2}
+	m.Reserve(ctx, other)
+	m.CommitExternal(other, 0, common.Hash{2}, time.Now().Add(-time.Second))
+	if gaps, err := m.Resync(ctx, other); err != nil || len(gaps) != 0 || m.Status(other).Next != 0 {
+		t.Fatalf("expired hold: gaps = %v, status %+v, err %v", gaps, m.Status(other), err)
+	}
+}
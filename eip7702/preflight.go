@@ -0,0 +1,280 @@
+package eip7702
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ===== ABI ERC-20 (apenas o que o preflight usa) =====
+var erc20ABI abi.ABI
+
+func init() {
+	const abiJSON = `[
+		{"type": "function", "name": "transfer", "inputs": [
+			{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"type": "bool"}]},
+		{"type": "function", "name": "transferFrom", "inputs": [
+			{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"type": "bool"}]},
+		{"type": "function", "name": "balanceOf", "stateMutability": "view", "inputs": [
+			{"name": "account", "type": "address"}], "outputs": [{"type": "uint256"}]},
+		{"type": "function", "name": "allowance", "stateMutability": "view", "inputs": [
+			{"name": "owner", "type": "address"}, {"name": "spender", "type": "address"}], "outputs": [{"type": "uint256"}]}
+	]`
+
+	var err error
+	erc20ABI, err = abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse ERC20 ABI: %v", err))
+	}
+}
+
+// maxExecuteDepth limita execute() aninhado ao decodificar as calls
+const maxExecuteDepth = 4
+
+// PreflightCheck identifica o que faltou para a authority
+type PreflightCheck string
+
+const (
+	CheckETHBalance     PreflightCheck = "eth_balance"     // ETH da authority < valor enviado pelas calls
+	CheckTokenBalance   PreflightCheck = "token_balance"   // balanceOf(conta) < total transferido
+	CheckTokenAllowance PreflightCheck = "token_allowance" // allowance(owner, authority) < total do transferFrom
+)
+
+// PreflightFailure descreve uma verificação que falhou
+type PreflightFailure struct {
+	Check     PreflightCheck  `json:"check"`
+	Account   common.Address  `json:"account"`
+	Token     *common.Address `json:"token,omitempty"`
+	Spender   *common.Address `json:"spender,omitempty"`
+	Required  *big.Int        `json:"required"`
+	Available *big.Int        `json:"available"`
+}
+
+// PreflightError é devolvido quando as calls certamente reverteriam por falta de saldo/allowance
+type PreflightError struct {
+	Failures []PreflightFailure
+}
+
+func (e *PreflightError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		subject := f.Account.Hex()
+		if f.Token != nil {
+			subject = fmt.Sprintf("%s on token %s", subject, f.Token.Hex())
+		}
+		parts[i] = fmt.Sprintf("%s of %s: has %s, needs %s", f.Check, subject, f.Available, f.Required)
+	}
+	return "preflight failed: " + strings.Join(parts, "; ")
+}
+
+// tokenAccount é um saldo de token; tokenAllowance é uma allowance owner → spender
+type tokenAccount struct {
+	token, account common.Address
+}
+
+type tokenAllowance struct {
+	token, owner, spender common.Address
+}
+
+// preflightNeeds acumula quanto as calls vão gastar (ordem de aparição preservada)
+type preflightNeeds struct {
+	eth           *big.Int
+	balances      map[tokenAccount]*big.Int
+	balanceKeys   []tokenAccount
+	allowances    map[tokenAllowance]*big.Int
+	allowanceKeys []tokenAllowance
+}
+
+func newPreflightNeeds() *preflightNeeds {
+	return &preflightNeeds{
+		eth:        big.NewInt(0),
+		balances:   make(map[tokenAccount]*big.Int),
+		allowances: make(map[tokenAllowance]*big.Int),
+	}
+}
+
+func (n *preflightNeeds) addBalance(token, account common.Address, amount *big.Int) {
+	key := tokenAccount{token, account}
+	if _, ok := n.balances[key]; !ok {
+		n.balances[key] = big.NewInt(0)
+		n.balanceKeys = append(n.balanceKeys, key)
+	}
+	n.balances[key].Add(n.balances[key], amount)
+}
+
+func (n *preflightNeeds) addAllowance(token, owner, spender common.Address, amount *big.Int) {
+	key := tokenAllowance{token, owner, spender}
+	if _, ok := n.allowances[key]; !ok {
+		n.allowances[key] = big.NewInt(0)
+		n.allowanceKeys = append(n.allowanceKeys, key)
+	}
+	n.allowances[key].Add(n.allowances[key], amount)
+}
+
+// preflight confere se a authority consegue pagar o que as calls enviam: ETH
+// (valores e sendETH) e, para transfer/transferFrom de ERC-20, balanceOf e allowance.
+// Tokens que não respondem como ERC-20 são ignorados; a simulação cobre o resto.
+func (d *DelegationService) preflight(ctx context.Context, authority common.Address, calls []Call) error {
+	needs := newPreflightNeeds()
+	collectNeeds(authority, calls, needs)
+
+	var failures []PreflightFailure
+
+	if needs.eth.Sign() > 0 {
+		balance, err := d.RPC.BalanceAt(ctx, authority)
+		if err != nil {
+			return fmt.Errorf("failed to get authority balance: %w", err)
+		}
+		if balance.Cmp(needs.eth) < 0 {
+			failures = append(failures, PreflightFailure{
+				Check: CheckETHBalance, Account: authority, Required: needs.eth, Available: balance,
+			})
+		}
+	}
+
+	for _, key := range needs.balanceKeys {
+		balance, ok, err := d.erc20Uint(ctx, key.token, "balanceOf", key.account)
+		if err != nil {
+			return err
+		}
+		if ok && balance.Cmp(needs.balances[key]) < 0 {
+			token := key.token
+			failures = append(failures, PreflightFailure{
+				Check: CheckTokenBalance, Account: key.account, Token: &token,
+				Required: needs.balances[key], Available: balance,
+			})
+		}
+	}
+
+	for _, key := range needs.allowanceKeys {
+		allowance, ok, err := d.erc20Uint(ctx, key.token, "allowance", key.owner, key.spender)
+		if err != nil {
+			return err
+		}
+		if ok && allowance.Cmp(needs.allowances[key]) < 0 {
+			token, spender := key.token, key.spender
+			failures = append(failures, PreflightFailure{
+				Check: CheckTokenAllowance, Account: key.owner, Token: &token, Spender: &spender,
+				Required: needs.allowances[key], Available: allowance,
+			})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &PreflightError{Failures: failures}
+	}
+	return nil
+}
+
+// collectNeeds segue a mesma regra de buildCallData: a call única vai direto para a
+// authority (value da tx é 0); várias passam por execute, que envia o value de cada call
+func collectNeeds(authority common.Address, calls []Call, needs *preflightNeeds) {
+	if len(calls) == 1 {
+		collectDelegateCall(authority, calls[0].Data, needs, 0)
+		return
+	}
+	for _, call := range calls {
+		collectInnerCall(authority, call.To, call.Data, call.Value, needs, 0)
+	}
+}
+
+// collectInnerCall trata uma call feita pela authority dentro de execute
+func collectInnerCall(authority, to common.Address, data []byte, value *big.Int, needs *preflightNeeds, depth int) {
+	if value != nil {
+		needs.eth.Add(needs.eth, value)
+	}
+	if to == authority {
+		collectDelegateCall(authority, data, needs, depth+1)
+		return
+	}
+	collectTokenCall(authority, to, data, needs)
+}
+
+// collectDelegateCall decodifica uma função do SimpleDelegateContract executada na authority
+func collectDelegateCall(authority common.Address, data []byte, needs *preflightNeeds, depth int) {
+	if len(data) < 4 || depth > maxExecuteDepth {
+		return
+	}
+	method, err := simpleDelegateABI.MethodById(data[:4])
+	if err != nil {
+		return
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return
+	}
+
+	switch method.Name {
+	case "execute":
+		var inner []struct {
+			Data  []byte
+			To    common.Address
+			Value *big.Int
+		}
+		if err := method.Inputs.Copy(&inner, args); err != nil {
+			return
+		}
+		for _, call := range inner {
+			collectInnerCall(authority, call.To, call.Data, call.Value, needs, depth)
+		}
+	case "sendETH":
+		needs.eth.Add(needs.eth, args[1].(*big.Int))
+	case "transfer":
+		needs.addBalance(args[0].(common.Address), authority, args[2].(*big.Int))
+	case "transferFrom":
+		token, from, amount := args[0].(common.Address), args[1].(common.Address), args[3].(*big.Int)
+		needs.addBalance(token, from, amount)
+		needs.addAllowance(token, from, authority, amount)
+	}
+}
+
+// collectTokenCall decodifica transfer/transferFrom de ERC-20 chamados pela authority
+func collectTokenCall(authority, token common.Address, data []byte, needs *preflightNeeds) {
+	if len(data) < 4 {
+		return
+	}
+	method, err := erc20ABI.MethodById(data[:4])
+	if err != nil {
+		return
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return
+	}
+
+	switch method.Name {
+	case "transfer":
+		needs.addBalance(token, authority, args[1].(*big.Int))
+	case "transferFrom":
+		from, amount := args[0].(common.Address), args[2].(*big.Int)
+		needs.addBalance(token, from, amount)
+		needs.addAllowance(token, from, authority, amount)
+	}
+}
+
+// erc20Uint chama uma view uint256 do token. ok = false se o contrato não respondeu
+// como ERC-20 (revert ou retorno inválido); erro só para falhas do RPC.
+func (d *DelegationService) erc20Uint(ctx context.Context, token common.Address, method string, args ...interface{}) (*big.Int, bool, error) {
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	ret, err := d.RPC.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		if _, ok := revertData(err); ok || isExecutionReverted(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to call %s on %s: %w", method, token.Hex(), err)
+	}
+	values, err := erc20ABI.Unpack(method, ret)
+	if err != nil || len(values) != 1 {
+		return nil, false, nil
+	}
+	v, ok := values[0].(*big.Int)
+	return v, ok, nil
+}
@@ -0,0 +1,164 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// tokenRPC responde balanceOf/allowance de ERC-20 e o saldo de ETH da authority
+type tokenRPC struct {
+	EthClient
+	eth        *big.Int
+	balances   map[tokenAccount]*big.Int
+	allowances map[tokenAllowance]*big.Int
+	reverts    common.Address // não é ERC-20: reverte
+	garbage    common.Address // responde dados que não decodificam
+	down       common.Address // falha de RPC
+}
+
+func (r *tokenRPC) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return r.eth, nil
+}
+
+func (r *tokenRPC) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	switch *msg.To {
+	case r.reverts:
+		return nil, revertError{}
+	case r.garbage:
+		return []byte{1}, nil
+	case r.down:
+		return nil, errors.New("connection refused")
+	}
+	method, err := erc20ABI.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	v := big.NewInt(0)
+	switch method.Name {
+	case "balanceOf":
+		if b, ok := r.balances[tokenAccount{*msg.To, args[0].(common.Address)}]; ok {
+			v = b
+		}
+	case "allowance":
+		if a, ok := r.allowances[tokenAllowance{*msg.To, args[0].(common.Address), args[1].(common.Address)}]; ok {
+			v = a
+		}
+	}
+	return method.Outputs.Pack(v)
+}
+
+func TestPreflight(t *testing.T) {
+	authority := common.Address{0xa0}
+	owner := common.Address{0xb0}
+	recipient := common.Address{0xc0}
+	tokenA, tokenB := common.Address{0x0a}, common.Address{0x0b}
+
+	rpc := &tokenRPC{
+		eth: big.NewInt(1),
+		balances: map[tokenAccount]*big.Int{
+			{tokenA, authority}: big.NewInt(50),
+			{tokenB, owner}:     big.NewInt(100),
+		},
+		allowances: map[tokenAllowance]*big.Int{
+			{tokenB, owner, authority}: big.NewInt(20),
+		},
+		reverts: common.Address{0xee},
+		garbage: common.Address{0xef},
+		down:    common.Address{0xdd},
+	}
+	d := &DelegationService{RPC: rpc}
+
+	pack := func(method string, args ...interface{}) []byte {
+		data, err := erc20ABI.Pack(method, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	delegate := func(method string, args ...interface{}) []byte {
+		data, err := simpleDelegateABI.Pack(method, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	transferA := func(amount int64) Call {
+		return Call{To: tokenA, Data: pack("transfer", recipient, big.NewInt(amount))}
+	}
+
+	t.Run("reports every shortfall", func(t *testing.T) {
+		calls := []Call{
+			transferA(30),
+			transferA(30), // somado: 60 > 50
+			{To: tokenB, Data: pack("transferFrom", owner, recipient, big.NewInt(30))},
+			{To: recipient, Value: big.NewInt(2)},
+		}
+		err := d.preflight(context.Background(), authority, calls)
+		var pe *PreflightError
+		if !errors.As(err, &pe) {
+			t.Fatalf("err = %v, want PreflightError", err)
+		}
+		want := []struct {
+			check     PreflightCheck
+			account   common.Address
+			required  int64
+			available int64
+		}{
+			{CheckETHBalance, authority, 2, 1},
+			{CheckTokenBalance, authority, 60, 50},
+			{CheckTokenAllowance, owner, 30, 20},
+		}
+		if len(pe.Failures) != len(want) {
+			t.Fatalf("failures = %+v", pe.Failures)
+		}
+		for i, w := range want {
+			f := pe.Failures[i]
+			if f.Check != w.check || f.Account != w.account || f.Required.Int64() != w.required || f.Available.Int64() != w.available {
+				t.Fatalf("failure %d = %+v, want %+v", i, f, w)
+			}
+		}
+		if *pe.Failures[2].Spender != authority || *pe.Failures[2].Token != tokenB {
+			t.Fatalf("allowance failure = %+v", pe.Failures[2])
+		}
+	})
+
+	tests := []struct {
+		name    string
+		calls   []Call
+		wantErr bool
+	}{
+		{"enough balance", []Call{transferA(20), transferA(30)}, false},
+		{"delegate transfer", []Call{{To: authority, Data: delegate("transfer", tokenA, recipient, big.NewInt(50))}}, false},
+		{"non-ERC-20 token is skipped", []Call{transferA(1), {To: rpc.reverts, Data: pack("transfer", recipient, big.NewInt(1))}}, false},
+		{"undecodable return is skipped", []Call{transferA(1), {To: rpc.garbage, Data: pack("transfer", recipient, big.NewInt(1))}}, false},
+		{"rpc failure", []Call{transferA(1), {To: rpc.down, Data: pack("transfer", recipient, big.NewInt(1))}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := d.preflight(context.Background(), authority, tt.calls)
+			var pe *PreflightError
+			if errors.As(err, &pe) {
+				t.Fatalf("unexpected preflight failure: %v", err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Call única pelo contrato delegado: o saldo checado é o da authority
+	err := d.preflight(context.Background(), authority, []Call{{To: authority, Data: delegate("transfer", tokenA, recipient, big.NewInt(51))}})
+	var pe *PreflightError
+	if !errors.As(err, &pe) || pe.Failures[0].Check != CheckTokenBalance || pe.Failures[0].Required.Int64() != 51 {
+		t.Fatalf("err = %v", err)
+	}
+}
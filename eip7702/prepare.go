@@ -0,0 +1,137 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// PrepareAuthorizationRequest pede os dados para a authority assinar no cliente
+type PrepareAuthorizationRequest struct {
+	Authority       string `json:"authority"`        // EOA que vai delegar
+	ContractAddress string `json:"contract_address"` // Contrato para delegar (zero = revogação)
+	SelfSponsored   bool   `json:"self_sponsored"`   // A própria authority enviará a tx
+	Universal       bool   `json:"universal"`        // chain_id = 0
+}
+
+// PreparedAuthorization contém exatamente o que a authority deve assinar
+type PreparedAuthorization struct {
+	Authority   common.Address `json:"authority"`
+	ChainID     uint64         `json:"chain_id"`
+	Address     common.Address `json:"address"`
+	Nonce       uint64         `json:"nonce"`
+	SigningHash common.Hash    `json:"signing_hash"` // keccak(0x05 || rlp([chain_id, address, nonce]))
+}
+
+// SubmitAuthorizationRequest traz a assinatura feita no cliente (sem chave privada)
+type SubmitAuthorizationRequest struct {
+	Authority       string `json:"authority"`
+	ContractAddress string `json:"contract_address"`
+	ChainID         uint64 `json:"chain_id"`
+	Nonce           uint64 `json:"nonce"`
+	YParity         uint8  `json:"y_parity"`
+	R               string `json:"r"` // hex, 32 bytes
+	S               string `json:"s"` // hex, 32 bytes
+	SelfSponsored   bool   `json:"self_sponsored"`
+}
+
+// PrepareAuthorization devolve chain_id, nonce e o hash que a authority deve assinar.
+// contractAddr zero prepara uma revogação, aceita só por /revoke.
+func (d *DelegationService) PrepareAuthorization(ctx context.Context, authority, contractAddr common.Address, opts DelegationOptions) (*PreparedAuthorization, error) {
+	if d == nil || d.RPC == nil || d.ChainID == nil {
+		return nil, errors.New("service not properly initialized")
+	}
+	if (authority == common.Address{}) {
+		return nil, errors.New("authority address is zero")
+	}
+	if (contractAddr != common.Address{}) {
+		if err := d.checkDelegateContract(contractAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	chainID, nonce, err := d.authorizationParams(ctx, authority, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := authorizationHash(chainID, contractAddr, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedAuthorization{
+		Authority:   authority,
+		ChainID:     chainID,
+		Address:     contractAddr,
+		Nonce:       nonce,
+		SigningHash: hash,
+	}, nil
+}
+
+// SubmitAuthorization monta a Authorization a partir da assinatura do cliente e a valida
+func (d *DelegationService) SubmitAuthorization(ctx context.Context, req *SubmitAuthorizationRequest) (*Authorization, error) {
+	if !common.IsHexAddress(req.Authority) {
+		return nil, errors.New("invalid authority address")
+	}
+	if !common.IsHexAddress(req.ContractAddress) {
+		return nil, errors.New("invalid contract address")
+	}
+	contractAddr := common.HexToAddress(req.ContractAddress)
+
+	r, err := parseSignatureWord(req.R)
+	if err != nil {
+		return nil, fmt.Errorf("invalid r: %w", err)
+	}
+	s, err := parseSignatureWord(req.S)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s: %w", err)
+	}
+
+	auth := &Authorization{
+		ChainID:   req.ChainID,
+		Address:   contractAddr,
+		Nonce:     req.Nonce,
+		V:         req.YParity,
+		R:         r,
+		S:         s,
+		Signer:    common.HexToAddress(req.Authority),
+		CreatedAt: time.Now().Unix(),
+	}
+
+	// sender desconhecido em fluxo patrocinado: qualquer conta diferente da authority.
+	// Self-sponsored: a próxima tx da authority entra no nonce pending.
+	var sender common.Address
+	var txNonce uint64
+	if req.SelfSponsored {
+		sender = auth.Signer
+		pending, err := d.RPC.PendingNonceAt(ctx, auth.Signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending nonce for %s: %w", auth.Signer.Hex(), err)
+		}
+		txNonce = pending
+	}
+	check := authorizationCheck{sender: sender, txNonce: txNonce, revocation: contractAddr == common.Address{}}
+	if err := d.validateAuthorization(ctx, auth, check); err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// parseSignatureWord converte um valor hex de até 32 bytes (r ou s)
+func parseSignatureWord(v string) ([32]byte, error) {
+	var out [32]byte
+	b, err := hexutil.Decode(v)
+	if err != nil {
+		return out, err
+	}
+	if len(b) == 0 || len(b) > 32 {
+		return out, fmt.Errorf("expected up to 32 bytes, got %d", len(b))
+	}
+	copy(out[32-len(b):], b)
+	return out, nil
+}
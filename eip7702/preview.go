@@ -0,0 +1,99 @@
+package eip7702
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// TxPreview é a tx montada e assinada, sem envio (broadcast: false).
+// RawTransaction pode ser enviado por outra infraestrutura via eth_sendRawTransaction.
+type TxPreview struct {
+	Hash              common.Hash      `json:"tx_hash"`
+	Type              uint8            `json:"type"`
+	ChainID           *big.Int         `json:"chain_id"`
+	From              common.Address   `json:"from"`
+	Nonce             uint64           `json:"nonce"`
+	To                *common.Address  `json:"to"`
+	Value             *big.Int         `json:"value"`
+	Data              hexutil.Bytes    `json:"data"`
+	Gas               uint64           `json:"gas"`
+	GasTipCap         *big.Int         `json:"max_priority_fee_per_gas"`
+	GasFeeCap         *big.Int         `json:"max_fee_per_gas"`
+	AuthorizationList []*Authorization `json:"authorization_list,omitempty"`
+
+	// SigningHash é o hash que o sponsor assina: keccak256(0x04 || rlp(campos sem assinatura))
+	SigningHash common.Hash `json:"signing_hash"`
+	// UnsignedTransaction é o preimage do SigningHash (só SetCodeTx)
+	UnsignedTransaction hexutil.Bytes `json:"unsigned_transaction,omitempty"`
+	// RawTransaction é a tx assinada no formato de envio (0x04 || rlp(...))
+	RawTransaction hexutil.Bytes `json:"raw_transaction"`
+}
+
+// PreviewTransaction descreve a tx assinada sem enviá-la. O nonce continua
+// reservado: quem entregar a raw tx deve chamar HandOffTransaction; quem a
+// descartar, DiscardTransaction.
+func (d *DelegationService) PreviewTransaction(tx *types.Transaction) (*TxPreview, error) {
+	signer := types.LatestSignerForChainID(d.ChainID)
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction signature: %w", err)
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	preview := &TxPreview{
+		Hash:           tx.Hash(),
+		Type:           tx.Type(),
+		ChainID:        tx.ChainId(),
+		From:           from,
+		Nonce:          tx.Nonce(),
+		To:             tx.To(),
+		Value:          tx.Value(),
+		Data:           tx.Data(),
+		Gas:            tx.Gas(),
+		GasTipCap:      tx.GasTipCap(),
+		GasFeeCap:      tx.GasFeeCap(),
+		SigningHash:    signer.Hash(tx),
+		RawTransaction: raw,
+	}
+	for _, sa := range tx.SetCodeAuthorizations() {
+		preview.AuthorizationList = append(preview.AuthorizationList, fromSetCodeAuthorization(sa))
+	}
+
+	if tx.Type() == types.SetCodeTxType {
+		unsigned, err := unsignedSetCodeTx(tx)
+		if err != nil {
+			return nil, err
+		}
+		preview.UnsignedTransaction = unsigned
+	}
+	return preview, nil
+}
+
+// unsignedSetCodeTx codifica os campos assinados na mesma ordem do signer do geth (Prague)
+func unsignedSetCodeTx(tx *types.Transaction) ([]byte, error) {
+	payload, err := rlp.EncodeToBytes([]interface{}{
+		tx.ChainId(),
+		tx.Nonce(),
+		tx.GasTipCap(),
+		tx.GasFeeCap(),
+		tx.Gas(),
+		tx.To(),
+		tx.Value(),
+		tx.Data(),
+		tx.AccessList(),
+		tx.SetCodeAuthorizations(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode unsigned transaction: %w", err)
+	}
+	return append([]byte{types.SetCodeTxType}, payload...), nil
+}
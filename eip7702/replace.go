@@ -0,0 +1,206 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// replacementBumpPercent é o aumento mínimo de gorjeta e fee cap que o mempool
+// (geth: txpool.pricebump) exige para aceitar outra tx no mesmo nonce
+const replacementBumpPercent = 10
+
+var (
+	// ErrTxNotReplaceable indica que a tx não está mais pendente (ou já foi substituída)
+	ErrTxNotReplaceable = errors.New("transaction cannot be replaced")
+	// ErrReplacementFeeTooLow indica que o teto do request não cobre o aumento mínimo
+	ErrReplacementFeeTooLow = errors.New("max fee per gas below replacement minimum")
+)
+
+// ReplacementResult descreve a tx substituta enviada por speedup/cancel
+type ReplacementResult struct {
+	Replaced common.Hash `json:"replaced"`
+	TxHash   common.Hash `json:"tx_hash"`
+	Nonce    uint64      `json:"nonce"`
+	Fees     *Fees       `json:"fees"`
+}
+
+// SpeedUp reassina a mesma tx (mesmo tipo, nonce, calldata e AuthList) com fees
+// aumentadas em pelo menos o mínimo de substituição e envia no lugar da original.
+// Uma cancelada (transferência comum) continua comum: não ganha uma AuthList vazia.
+func (d *DelegationService) SpeedUp(ctx context.Context, hash common.Hash, sponsor Signer, opts SponsorOptions) (*ReplacementResult, error) {
+	tracked, orig, err := d.replaceable(hash, sponsor)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := d.replacementFees(ctx, orig, opts.MaxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	var inner types.TxData
+	switch orig.Type() {
+	case types.SetCodeTxType:
+		inner = &types.SetCodeTx{
+			ChainID:    uint256.MustFromBig(d.ChainID),
+			Nonce:      orig.Nonce(),
+			GasTipCap:  uint256.MustFromBig(fees.GasTipCap),
+			GasFeeCap:  uint256.MustFromBig(fees.GasFeeCap),
+			Gas:        orig.Gas(),
+			To:         *orig.To(),
+			Value:      uint256.MustFromBig(orig.Value()),
+			Data:       orig.Data(),
+			AccessList: orig.AccessList(),
+			AuthList:   orig.SetCodeAuthorizations(),
+		}
+	case types.DynamicFeeTxType:
+		inner = &types.DynamicFeeTx{
+			ChainID:    d.ChainID,
+			Nonce:      orig.Nonce(),
+			GasTipCap:  fees.GasTipCap,
+			GasFeeCap:  fees.GasFeeCap,
+			Gas:        orig.Gas(),
+			To:         orig.To(),
+			Value:      orig.Value(),
+			Data:       orig.Data(),
+			AccessList: orig.AccessList(),
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported tx type %d", ErrTxNotReplaceable, orig.Type())
+	}
+	tx, err := sponsor.SignTx(ctx, types.NewTx(inner), d.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.sendReplacement(ctx, tracked, tx); err != nil {
+		return nil, err
+	}
+	// As autorizações agora seguem na substituta
+	if err := d.markSubmitted(tx); err != nil {
+		return nil, fmt.Errorf("speedup %s sent, but failed to record authorizations: %w", tx.Hash().Hex(), err)
+	}
+	return &ReplacementResult{Replaced: hash, TxHash: tx.Hash(), Nonce: tx.Nonce(), Fees: fees}, nil
+}
+
+// Cancel ocupa o nonce da tx com uma transferência de 0 ETH do sponsor para si mesmo.
+// Se a cancelada for minerada, a original nunca será e as autorizações não são usadas.
+func (d *DelegationService) Cancel(ctx context.Context, hash common.Hash, sponsor Signer, opts SponsorOptions) (*ReplacementResult, error) {
+	tracked, orig, err := d.replaceable(hash, sponsor)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := d.replacementFees(ctx, orig, opts.MaxFeePerGas)
+	if err != nil {
+		return nil, err
+	}
+
+	to := sponsor.Address()
+	tx, err := sponsor.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+		ChainID:   d.ChainID,
+		Nonce:     orig.Nonce(),
+		GasTipCap: fees.GasTipCap,
+		GasFeeCap: fees.GasFeeCap,
+		Gas:       params.TxGas,
+		To:        &to,
+		Value:     big.NewInt(0),
+	}), d.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.sendReplacement(ctx, tracked, tx); err != nil {
+		return nil, err
+	}
+	// A cancelada não leva as autorizações: elas podem ser enviadas de novo
+	if err := d.releaseSubmitted(orig); err != nil {
+		return nil, fmt.Errorf("cancellation %s sent, but failed to release authorizations: %w", tx.Hash().Hex(), err)
+	}
+	return &ReplacementResult{Replaced: hash, TxHash: tx.Hash(), Nonce: tx.Nonce(), Fees: fees}, nil
+}
+
+// replaceable devolve a tx original retida pelo tracker, desde que ainda pendente
+// e enviada pelo sponsor informado
+func (d *DelegationService) replaceable(hash common.Hash, sponsor Signer) (*TrackedTx, *types.Transaction, error) {
+	if d.Tracker == nil {
+		return nil, nil, errors.New("transaction tracker not configured")
+	}
+	tracked, orig, err := d.Tracker.transaction(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tracked.Status != TxPending {
+		return nil, nil, fmt.Errorf("%w: status is %s", ErrTxNotReplaceable, tracked.Status)
+	}
+	if tracked.ReplacedBy != nil {
+		return nil, nil, fmt.Errorf("%w: already replaced by %s", ErrTxNotReplaceable, tracked.ReplacedBy.Hex())
+	}
+	if sponsor.Address() != tracked.From {
+		return nil, nil, fmt.Errorf("sponsor %s did not send transaction %s", sponsor.Address().Hex(), hash.Hex())
+	}
+	return tracked, orig, nil
+}
+
+// replacementFees calcula as fees da substituta: o maior entre a original + 10%
+// e a sugestão atual, limitado por maxFee (que precisa cobrir o aumento mínimo)
+func (d *DelegationService) replacementFees(ctx context.Context, orig *types.Transaction, maxFee *big.Int) (*Fees, error) {
+	minTip := bumpFee(orig.GasTipCap())
+	minFeeCap := bumpFee(orig.GasFeeCap())
+	if maxFee != nil && maxFee.Cmp(minFeeCap) < 0 {
+		return nil, fmt.Errorf("%w: max %s, minimum %s", ErrReplacementFeeTooLow, maxFee, minFeeCap)
+	}
+
+	suggested, err := d.suggestFees(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tip := bigMax(minTip, suggested.GasTipCap)
+	feeCap := bigMax(minFeeCap, suggested.GasFeeCap)
+	if maxFee != nil && feeCap.Cmp(maxFee) > 0 {
+		feeCap = new(big.Int).Set(maxFee)
+	}
+	// A gorjeta nunca pode passar do teto
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+	if tip.Cmp(minTip) < 0 {
+		return nil, fmt.Errorf("%w: tip %s, minimum %s", ErrReplacementFeeTooLow, tip, minTip)
+	}
+	return &Fees{BaseFee: suggested.BaseFee, GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+// sendReplacement envia a substituta direto ao node: o nonce continua ocupado pela
+// original, então uma falha aqui não devolve nada ao NonceManager
+func (d *DelegationService) sendReplacement(ctx context.Context, tracked *TrackedTx, tx *types.Transaction) error {
+	if err := ClassifyRPCError(d.RPC.SendTransaction(context.WithoutCancel(ctx), tx)); err != nil && !errors.Is(err, ErrTxAlreadyKnown) {
+		return err
+	}
+	if d.Nonces != nil {
+		d.Nonces.Commit(tracked.From, tx.Nonce(), tx.Hash())
+	}
+	d.Tracker.Replace(tracked.Hash, tx, tracked.From)
+	return nil
+}
+
+// bumpFee devolve ceil(v * (100 + replacementBumpPercent) / 100)
+func bumpFee(v *big.Int) *big.Int {
+	out := new(big.Int).Mul(v, big.NewInt(100+replacementBumpPercent))
+	out.Add(out, big.NewInt(99))
+	return out.Div(out, big.NewInt(100))
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
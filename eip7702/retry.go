@@ -0,0 +1,150 @@
+package eip7702
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RetryPolicy controla quantas vezes uma falha transitória do RPC é repetida.
+// O intervalo começa em Backoff e dobra a cada tentativa, até MaxBackoff.
+type RetryPolicy struct {
+	Attempts   int // total de tentativas, incluindo a primeira
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy tenta 3 vezes: agora, após 250ms e após 500ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Attempts: 3, Backoff: 250 * time.Millisecond, MaxBackoff: 2 * time.Second}
+}
+
+// delay é a espera antes da tentativa attempt (1 = primeira repetição)
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// RetryingClient repete as chamadas de um EthClient que falham por motivo
+// transitório (transporte, timeout, rate limit) e devolve os erros classificados
+// por ClassifyRPCError. Recusas do node (nonce too low, revert...) não são repetidas.
+type RetryingClient struct {
+	rpc    EthClient
+	policy RetryPolicy
+}
+
+func NewRetryingClient(rpc EthClient, policy RetryPolicy) *RetryingClient {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	return &RetryingClient{rpc: rpc, policy: policy}
+}
+
+// withRetry executa call até dar certo, o erro não ser transitório ou as tentativas acabarem
+func withRetry[T any](ctx context.Context, p RetryPolicy, call func() (T, error)) (T, error) {
+	var v T
+	var err error
+	for attempt := 0; attempt < p.Attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return v, ClassifyRPCError(err)
+			case <-time.After(p.delay(attempt)):
+			}
+		}
+		v, err = call()
+		if err == nil || !isTransient(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	return v, ClassifyRPCError(err)
+}
+
+func (c *RetryingClient) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	return withRetry(ctx, c.policy, func() (uint64, error) { return c.rpc.NonceAt(ctx, from) })
+}
+
+func (c *RetryingClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return withRetry(ctx, c.policy, func() (uint64, error) { return c.rpc.PendingNonceAt(ctx, account) })
+}
+
+func (c *RetryingClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return withRetry(ctx, c.policy, func() (*big.Int, error) { return c.rpc.SuggestGasTipCap(ctx) })
+}
+
+func (c *RetryingClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return withRetry(ctx, c.policy, func() (*big.Int, error) { return c.rpc.ChainID(ctx) })
+}
+
+func (c *RetryingClient) BalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return withRetry(ctx, c.policy, func() (*big.Int, error) { return c.rpc.BalanceAt(ctx, account) })
+}
+
+func (c *RetryingClient) CodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return withRetry(ctx, c.policy, func() ([]byte, error) { return c.rpc.CodeAt(ctx, account) })
+}
+
+func (c *RetryingClient) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return withRetry(ctx, c.policy, func() (*types.Receipt, error) { return c.rpc.TransactionReceipt(ctx, hash) })
+}
+
+func (c *RetryingClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return withRetry(ctx, c.policy, func() (uint64, error) { return c.rpc.EstimateGas(ctx, msg) })
+}
+
+func (c *RetryingClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return withRetry(ctx, c.policy, func() (*types.Header, error) { return c.rpc.HeaderByNumber(ctx, number) })
+}
+
+func (c *RetryingClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return withRetry(ctx, c.policy, func() (*ethereum.FeeHistory, error) {
+		return c.rpc.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (c *RetryingClient) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	return withRetry(ctx, c.policy, func() ([]byte, error) { return c.rpc.CallContract(ctx, msg, codeOverrides) })
+}
+
+func (c *RetryingClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return withRetry(ctx, c.policy, func() (uint64, error) { return c.rpc.BlockNumber(ctx) })
+}
+
+func (c *RetryingClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	var pending bool
+	tx, err := withRetry(ctx, c.policy, func() (*types.Transaction, error) {
+		tx, isPending, err := c.rpc.TransactionByHash(ctx, hash)
+		pending = isPending
+		return tx, err
+	})
+	return tx, pending, err
+}
+
+func (c *RetryingClient) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, uint64, error) {
+	var gasUsed uint64
+	list, err := withRetry(ctx, c.policy, func() (types.AccessList, error) {
+		list, used, err := c.rpc.CreateAccessList(ctx, msg)
+		gasUsed = used
+		return list, err
+	})
+	return list, gasUsed, err
+}
+
+// SendTransaction repete o envio da mesma tx assinada (mesmo hash): se uma tentativa
+// chegou ao node apesar do erro, a seguinte recebe "already known" (ErrTxAlreadyKnown)
+func (c *RetryingClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := withRetry(ctx, c.policy, func() (struct{}, error) {
+		return struct{}{}, c.rpc.SendTransaction(ctx, tx)
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	transient := errors.New("connection refused")
+
+	tests := []struct {
+		name  string
+		errs  []error // erro de cada tentativa; depois delas a chamada dá certo
+		calls int
+		want  error
+	}{
+		{"success", nil, 1, nil},
+		{"transient then success", []error{transient}, 2, nil},
+		{"node rejection is not retried", []error{jsonRPCError{-32000, "nonce too low"}}, 1, ErrNonceTooLow},
+		{"revert is not retried", []error{jsonRPCError{3, "execution reverted"}}, 1, nil},
+		{"unknown rejection is not retried", []error{jsonRPCError{-32000, "something else"}}, 1, nil},
+		{"gives up after attempts", []error{transient, transient, transient, transient}, 3, ErrRPCUnavailable},
+		{"rate limit is retried", []error{jsonRPCError{-32005, "limit exceeded"}, transient}, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			v, err := withRetry(context.Background(), policy, func() (int, error) {
+				calls++
+				if calls <= len(tt.errs) {
+					return 0, tt.errs[calls-1]
+				}
+				return 42, nil
+			})
+			if calls != tt.calls {
+				t.Fatalf("calls = %d, want %d", calls, tt.calls)
+			}
+			if calls > len(tt.errs) {
+				if err != nil || v != 42 {
+					t.Fatalf("v = %d, err = %v", v, err)
+				}
+				return
+			}
+			var rpcErr *RPCError
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("err = %v, want *RPCError", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsOnCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := withRetry(ctx, RetryPolicy{Attempts: 5, Backoff: time.Hour}, func() (int, error) {
+		calls++
+		cancel()
+		return 0, errors.New("connection refused")
+	})
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+	if !errors.Is(err, ErrRPCUnavailable) {
+		t.Fatalf("err = %v, want ErrRPCUnavailable", err)
+	}
+}
@@ -0,0 +1,154 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Recusas comuns do node; use errors.Is sobre o erro devolvido pelo serviço
+var (
+	ErrNonceTooLow            = errors.New("nonce too low")
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	ErrFeeTooLow              = errors.New("transaction fee too low")
+	ErrInsufficientFunds      = errors.New("insufficient funds for gas * price + value")
+	ErrIntrinsicGasTooLow     = errors.New("intrinsic gas too low")
+	ErrAuthorizationRejected  = errors.New("authorization rejected by node")
+	ErrTxAlreadyKnown         = errors.New("transaction already known")
+	ErrUnsupported            = errors.New("method or transaction type not supported by node")
+	// ErrRPCUnavailable indica falha transitória (transporte, timeout, rate limit) após as tentativas
+	ErrRPCUnavailable = errors.New("rpc unavailable")
+)
+
+// RPCErrorCode é o código estável devolvido ao cliente HTTP em "code"
+type RPCErrorCode string
+
+const (
+	CodeNonceTooLow            RPCErrorCode = "nonce_too_low"
+	CodeReplacementUnderpriced RPCErrorCode = "replacement_underpriced"
+	CodeFeeTooLow              RPCErrorCode = "fee_too_low"
+	CodeInsufficientFunds      RPCErrorCode = "insufficient_funds"
+	CodeIntrinsicGasTooLow     RPCErrorCode = "intrinsic_gas_too_low"
+	CodeAuthorizationRejected  RPCErrorCode = "authorization_rejected"
+	CodeTxAlreadyKnown         RPCErrorCode = "already_known"
+	CodeUnsupported            RPCErrorCode = "unsupported"
+	CodeExecutionReverted      RPCErrorCode = "execution_reverted"
+	CodeRPCUnavailable         RPCErrorCode = "rpc_unavailable"
+	CodeRPCError               RPCErrorCode = "rpc_error"
+)
+
+// RPCError é um erro do node classificado. errors.Is casa com Kind (ex.:
+// ErrNonceTooLow) e errors.As continua alcançando o erro original do RPC.
+type RPCError struct {
+	Kind error // sentinel acima; nil para recusas não reconhecidas
+	Code RPCErrorCode
+	Err  error
+}
+
+func (e *RPCError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RPCError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Transient indica que repetir a mesma chamada pode dar certo
+func (e *RPCError) Transient() bool {
+	return e.Code == CodeRPCUnavailable
+}
+
+// rpcRejections mapeia trechos das mensagens do geth (e de outros clientes) para o tipo.
+// A ordem importa: "replacement transaction underpriced" antes de "underpriced".
+// As recusas de EIP-7702 casam só as mensagens do geth: "authorization" solto
+// também aparece em erros do provedor (ex.: header Authorization ausente).
+var rpcRejections = []struct {
+	match []string
+	kind  error
+	code  RPCErrorCode
+}{
+	{[]string{"nonce too low", "oldnonce"}, ErrNonceTooLow, CodeNonceTooLow},
+	{[]string{"replacement transaction underpriced", "replacement underpriced"}, ErrReplacementUnderpriced, CodeReplacementUnderpriced},
+	{[]string{"insufficient funds"}, ErrInsufficientFunds, CodeInsufficientFunds},
+	{[]string{"intrinsic gas too low", "floor data gas"}, ErrIntrinsicGasTooLow, CodeIntrinsicGasTooLow},
+	{[]string{
+		"eip-7702 transaction with empty auth list",
+		"eip-7702 transaction cannot be used to create contract",
+		"eip-7702 authorization", // chain ID mismatch, nonce, assinatura, destino com código
+		"authority already reserved",
+		"in-flight transaction limit reached for delegated accounts",
+		"gapped-nonce tx from delegated accounts",
+	}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+	{[]string{"underpriced", "fee cap less than block base fee", "max fee per gas less than"}, ErrFeeTooLow, CodeFeeTooLow},
+	{[]string{"already known", "alreadyknown"}, ErrTxAlreadyKnown, CodeTxAlreadyKnown},
+	{[]string{"transaction type not supported", "unsupported transaction type", "does not exist/is not available", "method not found"}, ErrUnsupported, CodeUnsupported},
+}
+
+// ClassifyRPCError converte o erro de uma chamada ao node em *RPCError. Erros que não
+// vieram do node (nil, contexto do request cancelado, já classificados) voltam como estão.
+func ClassifyRPCError(err error) error {
+	var classified *RPCError
+	if err == nil || errors.As(err, &classified) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	if !nodeAnswered(err) {
+		return &RPCError{Kind: ErrRPCUnavailable, Code: CodeRPCUnavailable, Err: err}
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+	// O motivo do revert vem do contrato e pode conter qualquer texto
+	if _, ok := revertData(err); ok || isExecutionReverted(err) {
+		return &RPCError{Code: CodeExecutionReverted, Err: err}
+	}
+
+	// -32601: o node não implementa o método
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return &RPCError{Kind: ErrUnsupported, Code: CodeUnsupported, Err: err}
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, r := range rpcRejections {
+		for _, m := range r.match {
+			if strings.Contains(msg, m) {
+				return &RPCError{Kind: r.kind, Code: r.code, Err: err}
+			}
+		}
+	}
+	return &RPCError{Code: CodeRPCError, Err: err}
+}
+
+// isTransient indica se a chamada pode ser repetida
+func isTransient(err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Transient()
+	}
+	return err != nil && !errors.Is(err, context.Canceled) && !nodeAnswered(err)
+}
+
+// nodeAnswered distingue uma resposta do node (que outro endpoint repetiria) de
+// uma falha de transporte, timeout ou limite de requisições
+func nodeAnswered(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return true
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005: limite de requisições do provedor
+		return rpcErr.ErrorCode() != -32005
+	}
+	return false
+}
+
+// isAlreadyKnown reconhece a recusa do node para tx que já está no mempool
+func isAlreadyKnown(err error) bool {
+	return errors.Is(ClassifyRPCError(err), ErrTxAlreadyKnown)
+}
@@ -0,0 +1,65 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+func TestClassifyRPCError(t *testing.T) {
+	// Mensagens como o geth (e provedores) respondem
+	tests := []struct {
+		name string
+		err  error
+		kind error
+		code RPCErrorCode
+	}{
+		{"nonce too low", jsonRPCError{-32000, "nonce too low: address 0x71C7656EC7ab88b098defB751B7401B5f6d8976F, tx: 1 state: 2"}, ErrNonceTooLow, CodeNonceTooLow},
+		{"replacement underpriced", jsonRPCError{-32000, "replacement transaction underpriced"}, ErrReplacementUnderpriced, CodeReplacementUnderpriced},
+		{"underpriced", jsonRPCError{-32000, "transaction underpriced: tip needed 1000000000, tip permitted 1"}, ErrFeeTooLow, CodeFeeTooLow},
+		{"below base fee", jsonRPCError{-32000, "max fee per gas less than block base fee: address 0x71C7656EC7ab88b098defB751B7401B5f6d8976F, maxFeePerGas: 1, baseFee: 7"}, ErrFeeTooLow, CodeFeeTooLow},
+		{"insufficient funds", jsonRPCError{-32000, "insufficient funds for gas * price + value: address 0x71C7656EC7ab88b098defB751B7401B5f6d8976F have 0 want 21000"}, ErrInsufficientFunds, CodeInsufficientFunds},
+		{"intrinsic gas", jsonRPCError{-32000, "intrinsic gas too low: gas 21000, minimum needed 46000"}, ErrIntrinsicGasTooLow, CodeIntrinsicGasTooLow},
+		{"floor data gas", jsonRPCError{-32000, "insufficient gas for floor data gas cost: gas 21000, minimum needed 21160"}, ErrIntrinsicGasTooLow, CodeIntrinsicGasTooLow},
+		{"empty auth list", jsonRPCError{-32000, "EIP-7702 transaction with empty auth list"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"set code create", jsonRPCError{-32000, "EIP-7702 transaction cannot be used to create contract"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"auth nonce mismatch", jsonRPCError{-32000, "EIP-7702 authorization nonce does not match current account nonce"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"authority reserved", jsonRPCError{-32000, "authority already reserved"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"in-flight limit", jsonRPCError{-32000, "in-flight transaction limit reached for delegated accounts"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"gapped nonce", jsonRPCError{-32000, "gapped-nonce tx from delegated accounts"}, ErrAuthorizationRejected, CodeAuthorizationRejected},
+		{"already known", jsonRPCError{-32000, "already known"}, ErrTxAlreadyKnown, CodeTxAlreadyKnown},
+		{"type not supported", jsonRPCError{-32000, "transaction type not supported"}, ErrUnsupported, CodeUnsupported},
+		{"method not found", jsonRPCError{-32601, "the method eth_fooBar does not exist/is not available"}, ErrUnsupported, CodeUnsupported},
+		{"reverted", jsonRPCError{3, "execution reverted: not owner"}, nil, CodeExecutionReverted},
+		// Recusas do provedor que citam "authorization" não são recusas de EIP-7702
+		{"provider auth header", jsonRPCError{-32000, "missing Authorization header"}, nil, CodeRPCError},
+		{"provider auth token", jsonRPCError{-32002, "unauthorized: invalid authorization token"}, nil, CodeRPCError},
+		{"rate limited", jsonRPCError{-32005, "limit exceeded"}, ErrRPCUnavailable, CodeRPCUnavailable},
+		{"transport", errors.New(`Post "http://localhost:8545": dial tcp 127.0.0.1:8545: connect: connection refused`), ErrRPCUnavailable, CodeRPCUnavailable},
+		{"timeout", context.DeadlineExceeded, ErrRPCUnavailable, CodeRPCUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyRPCError(tt.err)
+			var rpcErr *RPCError
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("err = %v, want *RPCError", err)
+			}
+			if rpcErr.Code != tt.code || rpcErr.Kind != tt.kind {
+				t.Fatalf("classified as %s/%v, want %s/%v", rpcErr.Code, rpcErr.Kind, tt.code, tt.kind)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatal("original error not reachable through errors.Is")
+			}
+		})
+	}
+
+	// Erros que não vieram do node voltam como estão
+	for _, err := range []error{nil, context.Canceled, ethereum.NotFound} {
+		if got := ClassifyRPCError(err); got != err {
+			t.Fatalf("ClassifyRPCError(%v) = %v", err, got)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrDeadlineExceeded indica que o deadline do request já passou
+	ErrDeadlineExceeded = errors.New("request deadline exceeded")
+	// ErrUnexpectedValue indica que a soma dos Call.Value difere do expected_value
+	ErrUnexpectedValue = errors.New("total call value differs from expected value")
+)
+
+// SponsorOptions converte os limites do request seguro em opções de execução.
+// MaxGasPrice é estrito: um fee cap calculado acima dele recusa a tx.
+func (r *SecureDelegationRequest) SponsorOptions() SponsorOptions {
+	return SponsorOptions{
+		MaxFeePerGas: r.MaxGasPrice,
+		StrictMaxFee: true,
+		Simulate:     r.Simulate,
+		AccessList:   r.AccessList,
+	}
+}
+
+// CheckDeadline recusa o request se o deadline (quando informado) já passou
+func (r *SecureDelegationRequest) CheckDeadline(now time.Time) error {
+	if r.Deadline != 0 && now.Unix() > r.Deadline {
+		return fmt.Errorf("%w: deadline %s", ErrDeadlineExceeded, time.Unix(r.Deadline, 0).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// CheckValue confere a soma dos Call.Value com o valor esperado (quando informado)
+func (r *SecureDelegationRequest) CheckValue(calls []Call) error {
+	if r.ExpectedValue == nil {
+		return nil
+	}
+	if total := totalCallValue(calls); total.Cmp(r.ExpectedValue) != 0 {
+		return fmt.Errorf("%w: calls send %s, expected %s", ErrUnexpectedValue, total, r.ExpectedValue)
+	}
+	return nil
+}
+
+// ExecuteSecure monta a tx patrocinada só se deadline, valor esperado e teto de
+// gas price forem respeitados. O deadline é conferido de novo após montar a tx;
+// quem envia deve conferir mais uma vez antes do broadcast.
+func (d *DelegationService) ExecuteSecure(ctx context.Context, req *SecureDelegationRequest, auth *Authorization, calls []Call, sponsor Signer) (*types.Transaction, *GasEstimate, error) {
+	if err := req.CheckDeadline(time.Now()); err != nil {
+		return nil, nil, err
+	}
+	if err := req.CheckValue(calls); err != nil {
+		return nil, nil, err
+	}
+
+	tx, gas, err := d.ExecuteSponsored(ctx, auth, calls, sponsor, req.SponsorOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Simulação e estimativa levam tempo: o deadline pode ter passado
+	if err := req.CheckDeadline(time.Now()); err != nil {
+		d.DiscardTransaction(tx)
+		return nil, nil, err
+	}
+	return tx, gas, nil
+}
+
+// totalCallValue soma o ETH enviado pelas calls
+func totalCallValue(calls []Call) *big.Int {
+	total := big.NewInt(0)
+	for _, call := range calls {
+		if call.Value != nil {
+			total.Add(total, call.Value)
+		}
+	}
+	return total
+}
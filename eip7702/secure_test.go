@@ -0,0 +1,114 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSecureRequestChecks(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	calls := []Call{
+		{To: common.Address{1}, Value: big.NewInt(3)},
+		{To: common.Address{2}}, // sem valor conta como zero
+		{To: common.Address{3}, Value: big.NewInt(4)},
+	}
+
+	tests := []struct {
+		name string
+		req  SecureDelegationRequest
+		want error
+	}{
+		{"no limits", SecureDelegationRequest{}, nil},
+		{"deadline ahead", SecureDelegationRequest{Deadline: now.Unix() + 60}, nil},
+		{"deadline is now", SecureDelegationRequest{Deadline: now.Unix()}, nil},
+		{"deadline passed", SecureDelegationRequest{Deadline: now.Unix() - 1}, ErrDeadlineExceeded},
+		{"expected value", SecureDelegationRequest{ExpectedValue: big.NewInt(7)}, nil},
+		{"value mismatch", SecureDelegationRequest{ExpectedValue: big.NewInt(6)}, ErrUnexpectedValue},
+		{"zero expected", SecureDelegationRequest{ExpectedValue: big.NewInt(0)}, ErrUnexpectedValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := errors.Join(tt.req.CheckDeadline(now), tt.req.CheckValue(calls))
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	opts := (&SecureDelegationRequest{MaxGasPrice: big.NewInt(5), Simulate: true}).SponsorOptions()
+	if !opts.StrictMaxFee || opts.MaxFeePerGas.Cmp(big.NewInt(5)) != 0 || !opts.Simulate {
+		t.Fatalf("opts = %+v", opts)
+	}
+}
+
+func TestExecuteSecureChecksBeforeBuilding(t *testing.T) {
+	// Sem RPC: qualquer consulta ao node falharia, então nada foi montado
+	d := &DelegationService{}
+	auth := &Authorization{ChainID: 1337, Address: common.Address{1}, Signer: common.Address{4}}
+	calls := []Call{{To: common.Address{4}, Value: big.NewInt(1)}}
+
+	expired := &SecureDelegationRequest{Deadline: time.Now().Unix() - 1}
+	if _, _, err := d.ExecuteSecure(context.Background(), expired, auth, calls, nil); !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrDeadlineExceeded", err)
+	}
+	mismatch := &SecureDelegationRequest{ExpectedValue: big.NewInt(2)}
+	if _, _, err := d.ExecuteSecure(context.Background(), mismatch, auth, calls, nil); !errors.Is(err, ErrUnexpectedValue) {
+		t.Fatalf("err = %v, want ErrUnexpectedValue", err)
+	}
+}
+
+// slowHeaders segura o header (consulta de fees) até until
+type slowHeaders struct {
+	EthClient
+	until time.Time
+}
+
+func (s *slowHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	time.Sleep(time.Until(s.until))
+	return s.EthClient.HeaderByNumber(ctx, number)
+}
+
+func TestExecuteSecureChecksDeadlineAfterBuilding(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// O deadline vale ao entrar e passa enquanto a tx é montada
+	deadline := time.Now().Unix()
+	env.svc.RPC = &slowHeaders{EthClient: env.chain, until: time.Unix(deadline+1, 0).Add(10 * time.Millisecond)}
+	req := &SecureDelegationRequest{Deadline: deadline}
+	if _, _, err := env.svc.ExecuteSecure(ctx, req, auth, []Call{env.mintCall(authority.Address())}, env.sponsor); !errors.Is(err, ErrDeadlineExceeded) {
+		t.Fatalf("err = %v, want ErrDeadlineExceeded", err)
+	}
+
+	// A tx montada foi descartada: nonce e autorização voltam a estar livres
+	if status := env.svc.Nonces.Status(env.sponsor.Address()); len(status.Reserved) != 0 || len(status.Pending) != 0 {
+		t.Fatalf("sponsor nonce still held: %+v", status)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.State != AuthorizationIssued {
+		t.Fatalf("state = %s, want issued", rec.State)
+	}
+
+	env.svc.RPC = env.chain
+	tx, _, err := env.svc.ExecuteSecure(ctx, &SecureDelegationRequest{Deadline: time.Now().Unix() + 60}, auth, []Call{env.mintCall(authority.Address())}, env.sponsor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 0 {
+		t.Fatalf("nonce = %d, want 0", tx.Nonce())
+	}
+}
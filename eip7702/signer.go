@@ -0,0 +1,206 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-chi/chi/v5"
+)
+
+// Signer assina hashes de autorização e transações sem expor a chave privada
+type Signer interface {
+	// Address retorna a conta que assina
+	Address() common.Address
+	// SignHash assina um hash de 32 bytes; retorna [R || S || V] com V em {0, 1}
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+	// SignTx assina a transação para a chain informada
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// signTxWithHash assina tx usando SignHash do signer (para signers sem acesso à chave)
+func signTxWithHash(ctx context.Context, s Signer, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	txSigner := types.LatestSignerForChainID(chainID)
+	sig, err := s.SignHash(ctx, txSigner.Hash(tx))
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(txSigner, sig)
+}
+
+// ===== CHAVE EM MEMÓRIA =====
+
+// KeySigner assina com uma chave privada em memória
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (k *KeySigner) Address() common.Address {
+	return k.address
+}
+
+func (k *KeySigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash.Bytes(), k.key)
+}
+
+func (k *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), k.key)
+}
+
+// ===== KEYSTORE CRIPTOGRAFADO =====
+
+// NewKeystoreSigner decifra um arquivo keystore JSON (go-ethereum) com a passphrase
+func NewKeystoreSigner(path, passphrase string) (*KeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore %s: %w", path, err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+// ===== SIGNER REMOTO (estilo web3signer) =====
+
+// RemoteSigner delega a assinatura para um serviço HTTP externo.
+// Protocolo: POST {baseURL}/api/v1/eth1/sign/{address} com {"data": "0x<hash>"};
+// a resposta é a assinatura hex de 65 bytes sobre o hash (sem hashing adicional).
+type RemoteSigner struct {
+	baseURL string
+	address common.Address
+	client  *http.Client
+}
+
+func NewRemoteSigner(baseURL string, address common.Address, client *http.Client) *RemoteSigner {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &RemoteSigner{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		address: address,
+		client:  client,
+	}
+}
+
+func (r *RemoteSigner) Address() common.Address {
+	return r.address
+}
+
+func (r *RemoteSigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{"data": hash.Hex()})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/v1/eth1/sign/%s", r.baseURL, r.address.Hex())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote signer response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote signer returned %d: %s", resp.StatusCode, strings.TrimSpace(string(out)))
+	}
+
+	sig, err := hexutil.Decode(strings.Trim(strings.TrimSpace(string(out)), `"`))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from remote signer: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length from remote signer: %d", len(sig))
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+
+	// Não confiar cegamente no serviço remoto: a assinatura deve ser da conta esperada
+	pub, err := crypto.SigToPub(hash.Bytes(), sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from remote signer: %w", err)
+	}
+	if crypto.PubkeyToAddress(*pub) != r.address {
+		return nil, errors.New("remote signer returned a signature from a different account")
+	}
+	return sig, nil
+}
+
+func (r *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return signTxWithHash(ctx, r, tx, chainID)
+}
+
+// NewRemoteSignerHandler serve o protocolo do RemoteSigner com signers locais.
+// Útil como substituto local do serviço remoto em testes e desenvolvimento.
+func NewRemoteSignerHandler(signers ...Signer) http.Handler {
+	byAddress := make(map[common.Address]Signer, len(signers))
+	for _, s := range signers {
+		byAddress[s.Address()] = s
+	}
+
+	r := chi.NewRouter()
+	r.Post("/api/v1/eth1/sign/{identifier}", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "identifier")
+		if !common.IsHexAddress(id) {
+			http.Error(w, "Invalid identifier", http.StatusBadRequest)
+			return
+		}
+		s, ok := byAddress[common.HexToAddress(id)]
+		if !ok {
+			http.Error(w, "Unknown account", http.StatusNotFound)
+			return
+		}
+
+		var req struct {
+			Data string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		data, err := hexutil.Decode(req.Data)
+		if err != nil || len(data) != common.HashLength {
+			http.Error(w, "data must be a 32-byte hex hash", http.StatusBadRequest)
+			return
+		}
+
+		sig, err := s.SignHash(r.Context(), common.BytesToHash(data))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to sign: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, hexutil.Encode(sig))
+	})
+	return r
+}
@@ -0,0 +1,132 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// lyingSigner anuncia um endereço e assina com outra chave
+type lyingSigner struct {
+	*KeySigner
+	claimed common.Address
+}
+
+func (l lyingSigner) Address() common.Address {
+	return l.claimed
+}
+
+func generateKeySigner(t *testing.T) *KeySigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewKeySigner(key)
+}
+
+func TestSigners(t *testing.T) {
+	local := generateKeySigner(t)
+
+	dir := t.TempDir()
+	account, err := keystore.StoreKey(dir, "secret", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromKeystore, err := NewKeystoreSigner(account.URL.Path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromKeystore.Address() != account.Address {
+		t.Fatalf("keystore address = %s, want %s", fromKeystore.Address(), account.Address)
+	}
+	if _, err := NewKeystoreSigner(account.URL.Path, "wrong"); err == nil {
+		t.Fatal("keystore decrypted with the wrong passphrase")
+	}
+
+	served := generateKeySigner(t)
+	server := httptest.NewServer(NewRemoteSignerHandler(served))
+	defer server.Close()
+	remote := NewRemoteSigner(server.URL+"/", served.Address(), nil)
+
+	ctx := context.Background()
+	hash := crypto.Keccak256Hash([]byte("eip7702"))
+	for name, s := range map[string]Signer{"key": local, "keystore": fromKeystore, "remote": remote} {
+		t.Run(name, func(t *testing.T) {
+			sig, err := s.SignHash(ctx, hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sig[64] > 1 {
+				t.Fatalf("v = %d, want 0 or 1", sig[64])
+			}
+			pub, err := crypto.SigToPub(hash.Bytes(), sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if crypto.PubkeyToAddress(*pub) != s.Address() {
+				t.Fatalf("recovered %s, want %s", crypto.PubkeyToAddress(*pub), s.Address())
+			}
+
+			chainID := big.NewInt(1337)
+			tx, err := s.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+				ChainID:   chainID,
+				Nonce:     3,
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: big.NewInt(2),
+				Gas:       21000,
+				To:        &common.Address{1},
+			}), chainID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+			if err != nil || from != s.Address() {
+				t.Fatalf("sender = %s, err %v, want %s", from, err, s.Address())
+			}
+		})
+	}
+}
+
+func TestRemoteSignerRejects(t *testing.T) {
+	honest := generateKeySigner(t)
+	liar := lyingSigner{KeySigner: generateKeySigner(t), claimed: generateKeySigner(t).Address()}
+	server := httptest.NewServer(NewRemoteSignerHandler(honest, liar))
+	defer server.Close()
+
+	hash := crypto.Keccak256Hash([]byte("eip7702"))
+	tests := []struct {
+		name    string
+		address common.Address
+		ctx     func() context.Context
+		want    string
+	}{
+		{"wrong recovered address", liar.claimed, context.Background, "different account"},
+		{"unknown account", common.Address{0xab}, context.Background, "404"},
+		{"canceled context", honest.Address(), func() context.Context {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx
+		}, "request failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx()
+			_, err := NewRemoteSigner(server.URL, tt.address, nil).SignHash(ctx, hash)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+			if ctx.Err() != nil && !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ===== ERROS CUSTOM CONHECIDOS (OpenZeppelin ERC20) =====
+var knownErrorsABI abi.ABI
+
+func init() {
+	const abiJSON = `[
+		{"type": "error", "name": "ERC20InsufficientBalance", "inputs": [
+			{"name": "sender", "type": "address"}, {"name": "balance", "type": "uint256"}, {"name": "needed", "type": "uint256"}]},
+		{"type": "error", "name": "ERC20InsufficientAllowance", "inputs": [
+			{"name": "spender", "type": "address"}, {"name": "allowance", "type": "uint256"}, {"name": "needed", "type": "uint256"}]},
+		{"type": "error", "name": "ERC20InvalidSender", "inputs": [{"name": "sender", "type": "address"}]},
+		{"type": "error", "name": "ERC20InvalidReceiver", "inputs": [{"name": "receiver", "type": "address"}]},
+		{"type": "error", "name": "ERC20InvalidApprover", "inputs": [{"name": "approver", "type": "address"}]},
+		{"type": "error", "name": "ERC20InvalidSpender", "inputs": [{"name": "spender", "type": "address"}]}
+	]`
+
+	var err error
+	knownErrorsABI, err = abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse errors ABI: %v", err))
+	}
+}
+
+// SimulateRequest é o payload de /simulate: authorization assinada ou authority + contrato
+type SimulateRequest struct {
+	Authorization   *Authorization `json:"authorization,omitempty"`
+	Authority       string         `json:"authority,omitempty"`
+	ContractAddress string         `json:"contract_address,omitempty"`
+	Calls           []CallData     `json:"calls"`
+	Sponsor         string         `json:"sponsor,omitempty"` // from da simulação (vazio = padrão)
+}
+
+// SimulationResult é o resultado do eth_call da tx patrocinada
+type SimulationResult struct {
+	Success      bool          `json:"success"`
+	ReturnData   hexutil.Bytes `json:"return_data,omitempty"`
+	RevertReason string        `json:"revert_reason,omitempty"`
+	RevertData   hexutil.Bytes `json:"revert_data,omitempty"`
+	CallIndex    *int          `json:"call_index,omitempty"` // call que reverteu (nil se não identificada)
+}
+
+// SimulationError é devolvido quando a simulação prevê revert: nada é assinado nem enviado
+type SimulationError struct {
+	Result *SimulationResult
+}
+
+func (e *SimulationError) Error() string {
+	if e.Result.CallIndex != nil {
+		return fmt.Sprintf("simulation reverted at call %d: %s", *e.Result.CallIndex, e.Result.RevertReason)
+	}
+	return fmt.Sprintf("simulation reverted: %s", e.Result.RevertReason)
+}
+
+// Simulate executa as calls via eth_call como a SetCodeTx faria (to = authority),
+// com state override instalando o designator 0xef0100 || delegate na authority.
+// Um revert não é erro: vem no resultado com o motivo decodificado e o índice da call.
+func (d *DelegationService) Simulate(ctx context.Context, from, authority, delegate common.Address, calls []Call) (*SimulationResult, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("no calls provided")
+	}
+
+	ret, revert, err := d.simulateCall(ctx, from, authority, delegate, buildCallData(calls))
+	if err != nil {
+		return nil, err
+	}
+	if revert == nil {
+		return &SimulationResult{Success: true, ReturnData: ret}, nil
+	}
+
+	result := &SimulationResult{RevertData: revert, RevertReason: decodeRevert(revert)}
+	idx, err := d.findFailingCall(ctx, from, authority, delegate, calls)
+	if err != nil {
+		return nil, err
+	}
+	result.CallIndex = idx
+	return result, nil
+}
+
+// findFailingCall simula prefixos crescentes do lote até achar a primeira call que reverte
+func (d *DelegationService) findFailingCall(ctx context.Context, from, authority, delegate common.Address, calls []Call) (*int, error) {
+	if len(calls) == 1 {
+		idx := 0
+		return &idx, nil
+	}
+	for i := 1; i <= len(calls); i++ {
+		_, revert, err := d.simulateCall(ctx, from, authority, delegate, buildCallData(calls[:i]))
+		if err != nil {
+			return nil, err
+		}
+		if revert != nil {
+			idx := i - 1
+			return &idx, nil
+		}
+	}
+	// O lote completo reverte mas nenhum prefixo: falha depende do conjunto (ex.: gas)
+	return nil, nil
+}
+
+// simulateCall devolve o retorno ou, se a execução reverteu, os dados do revert
+func (d *DelegationService) simulateCall(ctx context.Context, from, authority, delegate common.Address, data []byte) ([]byte, []byte, error) {
+	msg := ethereum.CallMsg{From: from, To: &authority, Data: data}
+	overrides := map[common.Address][]byte{
+		authority: types.AddressToDelegation(delegate),
+	}
+
+	ret, err := d.RPC.CallContract(ctx, msg, overrides)
+	if err == nil {
+		return ret, nil, nil
+	}
+	if revert, ok := revertData(err); ok {
+		return nil, revert, nil
+	}
+	if isExecutionReverted(err) {
+		return nil, []byte{}, nil // revert sem dados
+	}
+	return nil, nil, fmt.Errorf("simulation failed: %w", err)
+}
+
+// simulateSponsored roda a simulação antes de assinar e converte revert em SimulationError
+func (d *DelegationService) simulateSponsored(ctx context.Context, from common.Address, auth *Authorization, calls []Call) error {
+	result, err := d.Simulate(ctx, from, auth.Signer, auth.Address, calls)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return &SimulationError{Result: result}
+	}
+	return nil
+}
+
+// revertData extrai os dados do revert de um erro JSON-RPC (campo "data")
+func revertData(err error) ([]byte, bool) {
+	var de rpc.DataError
+	if !errors.As(err, &de) {
+		return nil, false
+	}
+	switch data := de.ErrorData().(type) {
+	case string:
+		b, err := hexutil.Decode(data)
+		return b, err == nil
+	case []byte:
+		return data, true
+	}
+	return nil, false
+}
+
+// decodeRevert traduz os dados do revert em texto. execute() repassa o revert
+// interno como string (require(success, string(result))), então o conteúdo
+// pode ser outro Error(string), um Panic ou um erro custom: decodifica em camadas.
+func decodeRevert(data []byte) string {
+	for range 4 {
+		if len(data) == 0 {
+			return "execution reverted without reason"
+		}
+
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			inner := []byte(reason)
+			if len(inner) >= 4 && isRevertPayload(inner) {
+				data = inner
+				continue
+			}
+			if reason == "" {
+				return "execution reverted without reason"
+			}
+			return reason
+		}
+
+		if reason, ok := decodeCustomError(data); ok {
+			return reason
+		}
+		return fmt.Sprintf("unknown revert data %s", hexutil.Encode(data))
+	}
+	return fmt.Sprintf("unknown revert data %s", hexutil.Encode(data))
+}
+
+// isRevertPayload indica se a string repassada é, na verdade, um revert ABI-encoded
+func isRevertPayload(data []byte) bool {
+	if _, err := abi.UnpackRevert(data); err == nil {
+		return true
+	}
+	_, ok := decodeCustomError(data)
+	return ok
+}
+
+// decodeCustomError decodifica os erros custom conhecidos do token
+func decodeCustomError(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	for _, e := range knownErrorsABI.Errors {
+		if !bytes.Equal(data[:4], e.ID[:4]) {
+			continue
+		}
+		values, err := e.Inputs.Unpack(data[4:])
+		if err != nil {
+			return "", false
+		}
+		args := make([]string, len(values))
+		for i, v := range values {
+			args[i] = fmt.Sprintf("%s=%v", e.Inputs[i].Name, v)
+		}
+		return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", ")), true
+	}
+	return "", false
+}
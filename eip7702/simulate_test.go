@@ -0,0 +1,125 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// revertError é o erro de eth_call com os dados do revert no campo "data"
+type revertError struct {
+	data []byte
+}
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorCode() int         { return 3 }
+func (e revertError) ErrorData() interface{} { return hexutil.Encode(e.data) }
+
+// revertingRPC reverte o eth_call sempre que o lote contém a call marcada
+type revertingRPC struct {
+	EthClient
+	marker []byte
+	revert []byte
+	calls  int
+}
+
+func (r *revertingRPC) CallContract(ctx context.Context, msg ethereum.CallMsg, codeOverrides map[common.Address][]byte) ([]byte, error) {
+	r.calls++
+	if bytes.Contains(msg.Data, r.marker) {
+		return nil, revertError{r.revert}
+	}
+	return nil, nil
+}
+
+func encodeRevert(t *testing.T, signature, typ string, value interface{}) []byte {
+	t.Helper()
+	abiType, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := abi.Arguments{{Type: abiType}}.Pack(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(crypto.Keccak256([]byte(signature))[:4], packed...)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	custom := knownErrorsABI.Errors["ERC20InsufficientBalance"]
+	packed, err := custom.Inputs.Pack(common.HexToAddress("0xaa"), big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	insufficient := append(custom.ID.Bytes()[:4], packed...)
+	overflow := encodeRevert(t, "Panic(uint256)", "uint256", big.NewInt(0x11))
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "execution reverted without reason"},
+		{"Error(string)", encodeRevert(t, "Error(string)", "string", "not owner"), "not owner"},
+		{"empty Error(string)", encodeRevert(t, "Error(string)", "string", ""), "execution reverted without reason"},
+		{"Panic(uint256)", overflow, "arithmetic underflow or overflow"},
+		{"custom error", insufficient, "ERC20InsufficientBalance(sender=0x00000000000000000000000000000000000000AA, balance=1, needed=2)"},
+		{"unknown selector", []byte{0xde, 0xad, 0xbe, 0xef}, "unknown revert data 0xdeadbeef"},
+		// execute() repassa o revert interno como string
+		{"wrapped Panic", encodeRevert(t, "Error(string)", "string", string(overflow)), "arithmetic underflow or overflow"},
+		{"wrapped custom error", encodeRevert(t, "Error(string)", "string", string(insufficient)), "ERC20InsufficientBalance("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeRevert(tt.data); !strings.HasPrefix(got, tt.want) {
+				t.Fatalf("decodeRevert = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulateFindsFailingCall(t *testing.T) {
+	marker := []byte("call 2 reverts")
+	rpc := &revertingRPC{
+		marker: marker,
+		revert: encodeRevert(t, "Error(string)", "string", "insufficient balance"),
+	}
+	d := &DelegationService{RPC: rpc}
+	calls := []Call{
+		{To: common.Address{1}, Data: []byte{0x01}, Value: big.NewInt(0)},
+		{To: common.Address{1}, Data: []byte{0x02}, Value: big.NewInt(0)},
+		{To: common.Address{1}, Data: marker, Value: big.NewInt(0)},
+		{To: common.Address{1}, Data: []byte{0x04}, Value: big.NewInt(0)},
+	}
+
+	result, err := d.Simulate(context.Background(), common.Address{2}, common.Address{3}, common.Address{4}, calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.RevertReason != "insufficient balance" {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.CallIndex == nil || *result.CallIndex != 2 {
+		t.Fatalf("call index = %v, want 2", result.CallIndex)
+	}
+	// Lote completo + prefixos de 1, 2 e 3 calls
+	if rpc.calls != 4 {
+		t.Fatalf("eth_call count = %d, want 4", rpc.calls)
+	}
+
+	// Sem a call marcada a simulação passa
+	result, err = d.Simulate(context.Background(), common.Address{2}, common.Address{3}, common.Address{4}, calls[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.CallIndex != nil {
+		t.Fatalf("result = %+v", result)
+	}
+}
@@ -0,0 +1,98 @@
+package eip7702
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SponsorRegistry guarda as contas patrocinadoras carregadas no startup.
+// As chaves ficam no servidor: requests escolhem o sponsor pelo nome.
+type SponsorRegistry struct {
+	signers     map[string]Signer
+	defaultName string
+}
+
+// SponsorInfo é a visão pública de um sponsor configurado
+type SponsorInfo struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	Default bool           `json:"default"`
+}
+
+func NewSponsorRegistry() *SponsorRegistry {
+	return &SponsorRegistry{signers: make(map[string]Signer)}
+}
+
+// Add registra um sponsor; o primeiro adicionado vira o padrão
+func (r *SponsorRegistry) Add(name string, signer Signer) error {
+	if name == "" {
+		return errors.New("sponsor name is empty")
+	}
+	if signer == nil {
+		return fmt.Errorf("sponsor %q has no signer", name)
+	}
+	if _, exists := r.signers[name]; exists {
+		return fmt.Errorf("sponsor %q already registered", name)
+	}
+
+	r.signers[name] = signer
+	if r.defaultName == "" {
+		r.defaultName = name
+	}
+	return nil
+}
+
+// SetDefault define o sponsor usado quando o request não informa nenhum
+func (r *SponsorRegistry) SetDefault(name string) error {
+	if _, ok := r.signers[name]; !ok {
+		return fmt.Errorf("unknown sponsor %q", name)
+	}
+	r.defaultName = name
+	return nil
+}
+
+// Get retorna o sponsor pelo nome; nome vazio usa o padrão
+func (r *SponsorRegistry) Get(name string) (Signer, error) {
+	if r == nil || len(r.signers) == 0 {
+		return nil, errors.New("no sponsors configured")
+	}
+	if name == "" {
+		name = r.defaultName
+	}
+
+	signer, ok := r.signers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown sponsor %q", name)
+	}
+	return signer, nil
+}
+
+// ByAddress procura o sponsor configurado para o endereço
+func (r *SponsorRegistry) ByAddress(addr common.Address) (Signer, bool) {
+	if r == nil {
+		return nil, false
+	}
+	for _, signer := range r.signers {
+		if signer.Address() == addr {
+			return signer, true
+		}
+	}
+	return nil, false
+}
+
+// List retorna os sponsors configurados ordenados por nome
+func (r *SponsorRegistry) List() []SponsorInfo {
+	if r == nil {
+		return nil
+	}
+
+	out := make([]SponsorInfo, 0, len(r.signers))
+	for name, signer := range r.signers {
+		out = append(out, SponsorInfo{Name: name, Address: signer.Address(), Default: name == r.defaultName})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
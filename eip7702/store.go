@@ -0,0 +1,705 @@
+package eip7702
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AuthorizationState é o estado de uma autorização no ciclo de vida do serviço
+type AuthorizationState string
+
+const (
+	AuthorizationIssued      AuthorizationState = "issued"      // assinada/aceita, ainda utilizável
+	AuthorizationSubmitting  AuthorizationState = "submitting"  // reservada por um request montando/enviando a tx
+	AuthorizationConsumed    AuthorizationState = "consumed"    // incluída em uma tx minerada
+	AuthorizationInvalidated AuthorizationState = "invalidated" // o nonce da authority avançou sem ela
+)
+
+// DefaultAuthorizationTTL é a validade padrão de uma autorização emitida/aceita
+const DefaultAuthorizationTTL = 5 * time.Minute
+
+// authorizationReservationTimeout é quanto tempo uma reserva (submitting) segura a
+// autorização; depois disso o request é dado como perdido e ela volta a ser utilizável
+const authorizationReservationTimeout = 2 * time.Minute
+
+// DefaultAuthorizationRetention é por quanto tempo registros fechados ou expirados
+// continuam consultáveis antes de SyncAuthorizations apagá-los
+const DefaultAuthorizationRetention = 24 * time.Hour
+
+// ErrAuthorizationNotFound indica que o store não conhece a autorização
+var ErrAuthorizationNotFound = errors.New("authorization not found")
+
+// AuthorizationRecord acompanha uma autorização do momento em que o servidor a vê
+type AuthorizationRecord struct {
+	ID            common.Hash        `json:"id"`
+	Authorization Authorization      `json:"authorization"`
+	State         AuthorizationState `json:"state"`
+	IssuedAt      time.Time          `json:"issued_at"`
+	ExpiresAt     time.Time          `json:"expires_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	TxHash        *common.Hash       `json:"tx_hash,omitempty"` // última tx enviada com esta autorização
+}
+
+// Expired indica se a validade definida pelo servidor já passou
+func (r *AuthorizationRecord) Expired(now time.Time) bool {
+	return now.After(r.ExpiresAt)
+}
+
+// settled indica que o nonce da autorização já foi usado (consumida ou invalidada)
+func (r *AuthorizationRecord) settled() bool {
+	return r.State == AuthorizationConsumed || r.State == AuthorizationInvalidated
+}
+
+// AuthorizationStore persiste o ciclo de vida das autorizações
+type AuthorizationStore interface {
+	Get(id common.Hash) (*AuthorizationRecord, error)
+	Put(rec *AuthorizationRecord) error
+	// Update aplica fn de forma atômica (compare-and-set): fn recebe uma cópia do
+	// registro atual (nil se não existe) e devolve o registro a gravar, ou nil para
+	// não mudar nada. Um erro de fn é devolvido sem gravar. Devolve o registro final.
+	Update(id common.Hash, fn UpdateFunc) (*AuthorizationRecord, error)
+	Delete(id common.Hash) error
+	List() ([]*AuthorizationRecord, error)
+}
+
+// UpdateFunc decide a nova versão de um registro em AuthorizationStore.Update
+type UpdateFunc func(cur *AuthorizationRecord) (*AuthorizationRecord, error)
+
+// AuthorizationID identifica a tupla assinada (chain_id, address, nonce, v, r, s)
+func AuthorizationID(auth *Authorization) common.Hash {
+	hash, _ := auth.SigningHash()
+	return crypto.Keccak256Hash(hash.Bytes(), []byte{auth.V}, auth.R[:], auth.S[:])
+}
+
+// ===== MEMÓRIA =====
+
+// MemoryAuthorizationStore mantém os registros em memória (perdidos ao reiniciar)
+type MemoryAuthorizationStore struct {
+	mu      sync.RWMutex
+	records map[common.Hash]*AuthorizationRecord
+}
+
+func NewMemoryAuthorizationStore() *MemoryAuthorizationStore {
+	return &MemoryAuthorizationStore{records: make(map[common.Hash]*AuthorizationRecord)}
+}
+
+func (m *MemoryAuthorizationStore) Get(id common.Hash) (*AuthorizationRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rec, ok := m.records[id]
+	if !ok {
+		return nil, ErrAuthorizationNotFound
+	}
+	cp := *rec
+	return &cp, nil
+}
+
+func (m *MemoryAuthorizationStore) Put(rec *AuthorizationRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp := *rec
+	m.records[rec.ID] = &cp
+	return nil
+}
+
+func (m *MemoryAuthorizationStore) Update(id common.Hash, fn UpdateFunc) (*AuthorizationRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next, err := applyUpdate(m.records[id], fn)
+	if err != nil || next == nil {
+		return next, err
+	}
+	stored := *next
+	m.records[id] = &stored
+	return next, nil
+}
+
+// applyUpdate roda fn sobre uma cópia de cur e devolve a versão final (nil = ausente)
+func applyUpdate(cur *AuthorizationRecord, fn UpdateFunc) (*AuthorizationRecord, error) {
+	var cp *AuthorizationRecord
+	if cur != nil {
+		c := *cur
+		cp = &c
+	}
+	next, err := fn(cp)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		return cp, nil
+	}
+	out := *next
+	return &out, nil
+}
+
+func (m *MemoryAuthorizationStore) Delete(id common.Hash) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, id)
+	return nil
+}
+
+func (m *MemoryAuthorizationStore) List() ([]*AuthorizationRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]*AuthorizationRecord, 0, len(m.records))
+	for _, rec := range m.records {
+		cp := *rec
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+// ===== ARQUIVO =====
+
+// fileStoreCompactMin é o mínimo de linhas no log antes de considerar compactar
+const fileStoreCompactMin = 1024
+
+// fileStoreEntry é uma linha do log: um registro gravado ou um ID apagado
+type fileStoreEntry struct {
+	Record  *AuthorizationRecord `json:"record,omitempty"`
+	Deleted *common.Hash         `json:"deleted,omitempty"`
+}
+
+// FileAuthorizationStore persiste os registros em um log JSON (uma entrada por linha).
+// Cada escrita só acrescenta uma linha; quando o log passa do dobro dos registros vivos
+// ele é regravado de forma atômica (tmp + rename) só com o estado atual.
+type FileAuthorizationStore struct {
+	mem     *MemoryAuthorizationStore
+	path    string
+	mu      sync.Mutex // serializa escritas no arquivo
+	file    *os.File   // aberto em modo append
+	entries int        // linhas no log
+}
+
+// NewFileAuthorizationStore abre (ou cria) o store no caminho informado.
+// Arquivos no formato antigo (array JSON) são convertidos na abertura.
+func NewFileAuthorizationStore(path string) (*FileAuthorizationStore, error) {
+	f := &FileAuthorizationStore{mem: NewMemoryAuthorizationStore(), path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read authorization store %s: %w", path, err)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []*AuthorizationRecord
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("failed to parse authorization store %s: %w", path, err)
+		}
+		for _, rec := range records {
+			f.mem.records[rec.ID] = rec
+		}
+		if err := f.compact(); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	torn, err := f.replay(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse authorization store %s: %w", path, err)
+	}
+	if torn {
+		// Regrava sem a linha incompleta para o próximo append não emendar nela
+		if err := f.compact(); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open authorization store %s: %w", path, err)
+	}
+	f.file = file
+	return f, nil
+}
+
+// replay aplica as linhas do log em memória. Uma última linha incompleta (queda no
+// meio de uma escrita) é ignorada e reportada em torn; qualquer outra linha inválida é erro.
+func (f *FileAuthorizationStore) replay(data []byte) (torn bool, err error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var pending error
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if pending != nil {
+			return false, pending
+		}
+		var entry fileStoreEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			pending = fmt.Errorf("line %d: %w", f.entries+1, err)
+			continue
+		}
+		f.entries++
+		switch {
+		case entry.Record != nil:
+			f.mem.records[entry.Record.ID] = entry.Record
+		case entry.Deleted != nil:
+			delete(f.mem.records, *entry.Deleted)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return false, err
+	}
+	return pending != nil || (len(data) > 0 && data[len(data)-1] != '\n'), nil
+}
+
+func (f *FileAuthorizationStore) Get(id common.Hash) (*AuthorizationRecord, error) {
+	return f.mem.Get(id)
+}
+
+func (f *FileAuthorizationStore) List() ([]*AuthorizationRecord, error) {
+	return f.mem.List()
+}
+
+func (f *FileAuthorizationStore) Put(rec *AuthorizationRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cp := *rec
+	if err := f.append(fileStoreEntry{Record: &cp}); err != nil {
+		return err
+	}
+	return f.mem.Put(rec)
+}
+
+func (f *FileAuthorizationStore) Update(id common.Hash, fn UpdateFunc) (*AuthorizationRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Só este store escreve em f.mem, sempre com f.mu: ler e gravar aqui é atômico
+	f.mem.mu.RLock()
+	cur := f.mem.records[id]
+	f.mem.mu.RUnlock()
+
+	var changed bool
+	next, err := applyUpdate(cur, func(cp *AuthorizationRecord) (*AuthorizationRecord, error) {
+		next, err := fn(cp)
+		changed = next != nil
+		return next, err
+	})
+	if err != nil || !changed {
+		return next, err
+	}
+	stored := *next
+	if err := f.append(fileStoreEntry{Record: &stored}); err != nil {
+		return nil, err
+	}
+	return next, f.mem.Put(next)
+}
+
+func (f *FileAuthorizationStore) Delete(id common.Hash) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.mem.Get(id); errors.Is(err, ErrAuthorizationNotFound) {
+		return nil
+	}
+	if err := f.append(fileStoreEntry{Deleted: &id}); err != nil {
+		return err
+	}
+	return f.mem.Delete(id)
+}
+
+// Close fecha o arquivo do log
+func (f *FileAuthorizationStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// append grava uma linha no log e compacta se ele cresceu demais. Chamado com f.mu.
+func (f *FileAuthorizationStore) append(entry fileStoreEntry) error {
+	if f.file == nil {
+		return errors.New("authorization store is closed")
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write authorization store: %w", err)
+	}
+	f.entries++
+
+	if f.entries >= fileStoreCompactMin && f.entries > 2*len(f.mem.records)+1 {
+		return f.compact()
+	}
+	return nil
+}
+
+// compact regrava o log só com os registros vivos e reabre o arquivo para append
+func (f *FileAuthorizationStore) compact() error {
+	records, _ := f.mem.List()
+	var buf bytes.Buffer
+	for _, rec := range records {
+		line, err := json.Marshal(fileStoreEntry{Record: rec})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".authorizations-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write authorization store: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write authorization store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write authorization store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write authorization store: %w", err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open authorization store %s: %w", f.path, err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.entries = len(records)
+	return nil
+}
+
+// ===== CICLO DE VIDA NO SERVIÇO =====
+
+// trackAuthorization devolve o registro da autorização, registrando-a como
+// emitida (com expiração definida pelo servidor) na primeira vez que é vista
+func (d *DelegationService) trackAuthorization(auth *Authorization) (*AuthorizationRecord, error) {
+	if d.Store == nil {
+		return nil, errors.New("authorization store not configured")
+	}
+
+	id := AuthorizationID(auth)
+	rec, err := d.Store.Update(id, func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+		if cur != nil {
+			return nil, nil
+		}
+		return d.newAuthorizationRecord(id, auth), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store authorization: %w", err)
+	}
+	return rec, nil
+}
+
+// issueAuthorization registra uma autorização assinada pelo serviço. Assinar de novo os
+// mesmos (chain_id, address, nonce) gera a mesma tupla: se ainda emitida, a validade recomeça.
+func (d *DelegationService) issueAuthorization(auth *Authorization) (*AuthorizationRecord, error) {
+	if d.Store == nil {
+		return nil, errors.New("authorization store not configured")
+	}
+
+	id := AuthorizationID(auth)
+	rec, err := d.Store.Update(id, func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+		if cur == nil {
+			return d.newAuthorizationRecord(id, auth), nil
+		}
+		if cur.State != AuthorizationIssued {
+			// Sendo enviada, consumida ou invalidada: a validação decide
+			return nil, nil
+		}
+		now := time.Now()
+		cur.IssuedAt = now
+		cur.ExpiresAt = now.Add(d.authorizationTTL())
+		cur.UpdatedAt = now
+		return cur, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store authorization: %w", err)
+	}
+	return rec, nil
+}
+
+// newAuthorizationRecord cria o registro emitido agora, com a validade do serviço
+func (d *DelegationService) newAuthorizationRecord(id common.Hash, auth *Authorization) *AuthorizationRecord {
+	now := time.Now()
+	return &AuthorizationRecord{
+		ID:            id,
+		Authorization: *auth,
+		State:         AuthorizationIssued,
+		IssuedAt:      now,
+		ExpiresAt:     now.Add(d.authorizationTTL()),
+		UpdatedAt:     now,
+	}
+}
+
+func (d *DelegationService) authorizationTTL() time.Duration {
+	if d.AuthorizationTTL <= 0 {
+		return DefaultAuthorizationTTL
+	}
+	return d.AuthorizationTTL
+}
+
+// checkAuthorizationRecord rejeita autorizações consumidas, invalidadas, reservadas por
+// outro request, já enviadas ou expiradas
+func checkAuthorizationRecord(rec *AuthorizationRecord) error {
+	switch rec.State {
+	case AuthorizationConsumed:
+		return errors.New("authorization already consumed")
+	case AuthorizationInvalidated:
+		return errors.New("authorization invalidated: authority nonce moved on")
+	case AuthorizationSubmitting:
+		if time.Since(rec.UpdatedAt) < authorizationReservationTimeout {
+			return errors.New("authorization already being submitted by another request")
+		}
+	}
+	if rec.TxHash != nil {
+		return fmt.Errorf("authorization already submitted in tx %s", rec.TxHash.Hex())
+	}
+	if rec.Expired(time.Now()) {
+		return fmt.Errorf("authorization expired at %s", rec.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// reserveAuthorization confere o registro e o marca como submitting no mesmo passo:
+// dois requests com a mesma autorização não passam os dois da validação
+func (d *DelegationService) reserveAuthorization(auth *Authorization) error {
+	id := AuthorizationID(auth)
+	_, err := d.Store.Update(id, func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+		if cur == nil {
+			return nil, ErrAuthorizationNotFound
+		}
+		if err := checkAuthorizationRecord(cur); err != nil {
+			return nil, err
+		}
+		cur.State = AuthorizationSubmitting
+		cur.UpdatedAt = time.Now()
+		return cur, nil
+	})
+	return err
+}
+
+// releaseReserved devolve ao estado issued as autorizações reservadas por um request
+// que não vai enviar a tx
+func (d *DelegationService) releaseReserved(auths []*Authorization) error {
+	if d.Store == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, auth := range auths {
+		_, err := d.Store.Update(AuthorizationID(auth), func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+			if cur == nil || cur.State != AuthorizationSubmitting {
+				return nil, nil
+			}
+			cur.State = AuthorizationIssued
+			cur.UpdatedAt = time.Now()
+			return cur, nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to release authorization: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// settleAuthorization fecha um registro cujo nonce a authority já usou:
+// consumido se a tx enviada pelo serviço foi minerada, invalidado caso contrário
+func (d *DelegationService) settleAuthorization(ctx context.Context, rec *AuthorizationRecord) error {
+	state := AuthorizationInvalidated
+	if rec.TxHash != nil {
+		// A AuthList é processada antes da execução: mesmo uma tx revertida consome a autorização
+		if receipt, err := d.RPC.TransactionReceipt(ctx, *rec.TxHash); err == nil && receipt != nil {
+			state = AuthorizationConsumed
+		}
+	}
+
+	_, err := d.Store.Update(rec.ID, func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+		// Outra tx assumiu a autorização durante a consulta: fica para o próximo sync
+		if cur == nil || cur.settled() || !sameHash(cur.TxHash, rec.TxHash) {
+			return nil, nil
+		}
+		cur.State = state
+		cur.UpdatedAt = time.Now()
+		return cur, nil
+	})
+	return err
+}
+
+func sameHash(a, b *common.Hash) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// settleTracked fecha o registro da autorização, se o serviço a conhece
+func (d *DelegationService) settleTracked(ctx context.Context, auth *Authorization) error {
+	if d.Store == nil {
+		return nil
+	}
+	rec, err := d.Store.Get(AuthorizationID(auth))
+	if errors.Is(err, ErrAuthorizationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return d.settleAuthorization(ctx, rec)
+}
+
+// markSubmitted associa a tx às autorizações da sua AuthList (encerrando a reserva)
+func (d *DelegationService) markSubmitted(tx *types.Transaction) error {
+	if d.Store == nil {
+		return nil
+	}
+
+	hash := tx.Hash()
+	for _, sa := range tx.SetCodeAuthorizations() {
+		_, err := d.Store.Update(AuthorizationID(fromSetCodeAuthorization(sa)), func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+			if cur == nil || cur.settled() {
+				return nil, nil
+			}
+			cur.State = AuthorizationIssued
+			cur.TxHash = &hash
+			cur.UpdatedAt = time.Now()
+			return cur, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store authorization: %w", err)
+		}
+	}
+	return nil
+}
+
+// releaseSubmitted desfaz markSubmitted para uma tx que não vai ser minerada (descartada
+// ou cancelada): as autorizações que ela levava voltam a poder ser enviadas
+func (d *DelegationService) releaseSubmitted(tx *types.Transaction) error {
+	return d.repointSubmitted(tx, nil)
+}
+
+// repointSubmitted passa para a tx to as autorizações que ainda apontam para tx
+// (nil desvincula)
+func (d *DelegationService) repointSubmitted(tx *types.Transaction, to *common.Hash) error {
+	if d.Store == nil {
+		return nil
+	}
+
+	hash := tx.Hash()
+	var errs []error
+	for _, sa := range tx.SetCodeAuthorizations() {
+		_, err := d.Store.Update(AuthorizationID(fromSetCodeAuthorization(sa)), func(cur *AuthorizationRecord) (*AuthorizationRecord, error) {
+			if cur == nil || cur.TxHash == nil || *cur.TxHash != hash {
+				return nil, nil // já segue em outra tx (speedup)
+			}
+			cur.TxHash = to
+			cur.UpdatedAt = time.Now()
+			return cur, nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to store authorization: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// releaseTransaction libera tudo o que uma tx assinada segurava nas autorizações:
+// a reserva (se ainda não enviada) e o vínculo com o hash (se o envio falhou)
+func (d *DelegationService) releaseTransaction(tx *types.Transaction) error {
+	auths := make([]*Authorization, 0, len(tx.SetCodeAuthorizations()))
+	for _, sa := range tx.SetCodeAuthorizations() {
+		auths = append(auths, fromSetCodeAuthorization(sa))
+	}
+	return errors.Join(d.releaseSubmitted(tx), d.releaseReserved(auths))
+}
+
+// SyncAuthorizations revisa as autorizações: fecha as emitidas que a authority já usou
+// (consumidas ou invalidadas) e apaga as fechadas ou expiradas sem tx há mais de
+// AuthorizationRetention. Pensado para rodar periodicamente; o erro de um registro
+// não interrompe os demais.
+func (d *DelegationService) SyncAuthorizations(ctx context.Context) error {
+	if d.Store == nil {
+		return errors.New("authorization store not configured")
+	}
+
+	records, err := d.Store.List()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var errs []error
+	for _, rec := range records {
+		if err := d.syncAuthorization(ctx, rec, now); err != nil {
+			errs = append(errs, fmt.Errorf("authorization %s: %w", rec.ID.Hex(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncAuthorization revisa um registro em SyncAuthorizations
+func (d *DelegationService) syncAuthorization(ctx context.Context, rec *AuthorizationRecord, now time.Time) error {
+	retention := d.AuthorizationRetention
+	if retention <= 0 {
+		retention = DefaultAuthorizationRetention
+	}
+
+	switch {
+	case rec.settled():
+		if now.Sub(rec.UpdatedAt) > retention {
+			return d.Store.Delete(rec.ID)
+		}
+		return nil
+	case rec.TxHash == nil && rec.Expired(now):
+		// Expirada e sem tx: não será mais enviada pelo serviço, não há o que consultar
+		if now.Sub(rec.ExpiresAt) > retention {
+			return d.Store.Delete(rec.ID)
+		}
+		return nil
+	}
+
+	nonce, err := d.RPC.NonceAt(ctx, rec.Authorization.Signer)
+	if err != nil {
+		return fmt.Errorf("failed to check nonce for %s: %w", rec.Authorization.Signer.Hex(), err)
+	}
+	if nonce > rec.Authorization.Nonce {
+		return d.settleAuthorization(ctx, rec)
+	}
+	return nil
+}
+
+// AuthorizationRecordByID consulta o ciclo de vida de uma autorização
+func (d *DelegationService) AuthorizationRecordByID(id common.Hash) (*AuthorizationRecord, error) {
+	if d.Store == nil {
+		return nil, errors.New("authorization store not configured")
+	}
+	return d.Store.Get(id)
+}
@@ -0,0 +1,490 @@
+package eip7702
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/omnes/eip7702/eip7702/fakechain"
+)
+
+// failingNonces é a fakechain com NonceAt falhando para uma conta
+type failingNonces struct {
+	*fakechain.Chain
+	fail common.Address
+}
+
+func (f *failingNonces) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	if from == f.fail {
+		return 0, errors.New("node unavailable")
+	}
+	return f.Chain.NonceAt(ctx, from)
+}
+
+func testRecord(id byte, state AuthorizationState, signer common.Address) *AuthorizationRecord {
+	now := time.Now()
+	return &AuthorizationRecord{
+		ID:            common.Hash{id},
+		Authorization: Authorization{Signer: signer},
+		State:         state,
+		IssuedAt:      now,
+		ExpiresAt:     now.Add(time.Minute),
+		UpdatedAt:     now,
+	}
+}
+
+func TestFileAuthorizationStoreAppendsAndReplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorizations.json")
+	store, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := testRecord(1, AuthorizationIssued, common.Address{1}), testRecord(2, AuthorizationIssued, common.Address{2})
+	for _, rec := range []*AuthorizationRecord{first, second} {
+		if err := store.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first.State = AuthorizationConsumed
+	if err := store.Put(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(second.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cada escrita é uma linha a mais, sem regravar as anteriores
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 4 {
+		t.Fatalf("log has %d lines, want 4", lines)
+	}
+
+	reopened, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	rec, err := reopened.Get(first.ID)
+	if err != nil || rec.State != AuthorizationConsumed {
+		t.Fatalf("first = %+v, err %v", rec, err)
+	}
+	if _, err := reopened.Get(second.ID); !errors.Is(err, ErrAuthorizationNotFound) {
+		t.Fatalf("deleted record: err = %v", err)
+	}
+}
+
+func TestFileAuthorizationStoreCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorizations.json")
+	store, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	rec := testRecord(1, AuthorizationIssued, common.Address{1})
+	for i := 0; i < fileStoreCompactMin; i++ {
+		if err := store.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines >= fileStoreCompactMin {
+		t.Fatalf("log has %d lines after %d writes of one record", lines, fileStoreCompactMin)
+	}
+}
+
+func TestFileAuthorizationStoreReadsLegacyArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorizations.json")
+	rec := testRecord(1, AuthorizationIssued, common.Address{1})
+	data, err := json.Marshal([]*AuthorizationRecord{rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(testRecord(2, AuthorizationIssued, common.Address{2})); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if records, _ := reopened.List(); len(records) != 2 {
+		t.Fatalf("%d records after conversion, want 2", len(records))
+	}
+}
+
+func TestFileAuthorizationStoreIgnoresTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorizations.json")
+	line, err := json.Marshal(fileStoreEntry{Record: testRecord(1, AuthorizationIssued, common.Address{1})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Queda no meio da segunda escrita
+	data := append(append(line, '\n'), line[:len(line)/2]...)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(testRecord(2, AuthorizationIssued, common.Address{2})); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened, err := NewFileAuthorizationStore(path)
+	if err != nil {
+		t.Fatalf("reopen after torn write: %v", err)
+	}
+	defer reopened.Close()
+	if records, _ := reopened.List(); len(records) != 2 {
+		t.Fatalf("%d records, want 2", len(records))
+	}
+}
+
+func TestSyncAuthorizationsCollectsGarbage(t *testing.T) {
+	env := newTestEnv(t)
+	unreachable, used := newTestSigner(t).Address(), newTestSigner(t).Address()
+	env.svc.RPC = &failingNonces{Chain: env.chain, fail: unreachable}
+	env.chain.SetNonce(used, 1)
+	old := time.Now().Add(-2 * env.svc.AuthorizationRetention)
+
+	settled := testRecord(1, AuthorizationConsumed, unreachable)
+	settled.UpdatedAt = old
+	expired := testRecord(2, AuthorizationIssued, unreachable)
+	expired.ExpiresAt = old
+	recentlyExpired := testRecord(3, AuthorizationIssued, unreachable)
+	recentlyExpired.ExpiresAt = time.Now().Add(-time.Second)
+	failing := testRecord(4, AuthorizationIssued, unreachable)
+	stale := testRecord(5, AuthorizationIssued, used)
+	for _, rec := range []*AuthorizationRecord{settled, expired, recentlyExpired, failing, stale} {
+		if err := env.svc.Store.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Só o registro ainda válido consulta o node; a falha dele não para os outros
+	err := env.svc.SyncAuthorizations(context.Background())
+	if err == nil || !strings.Contains(err.Error(), failing.ID.Hex()) || strings.Contains(err.Error(), recentlyExpired.ID.Hex()) {
+		t.Fatalf("err = %v, want only the failing record", err)
+	}
+	for _, id := range []common.Hash{settled.ID, expired.ID} {
+		if _, err := env.svc.Store.Get(id); !errors.Is(err, ErrAuthorizationNotFound) {
+			t.Errorf("record %s was not collected", id)
+		}
+	}
+	if _, err := env.svc.Store.Get(recentlyExpired.ID); err != nil {
+		t.Errorf("recently expired record: %v", err)
+	}
+	if rec, err := env.svc.Store.Get(stale.ID); err != nil || rec.State != AuthorizationInvalidated {
+		t.Errorf("stale record = %+v, err %v", rec, err)
+	}
+}
+
+func TestInvalidAuthorizationIsNotTracked(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	calls := []Call{env.mintCall(authority.Address())}
+
+	for name, auth := range map[string]*Authorization{
+		"wrong chain":  mustSign(t, env, authority, 5, 0),
+		"future nonce": mustSign(t, env, authority, testChainID.Uint64(), 3),
+	} {
+		if _, _, err := env.svc.ExecuteSponsored(ctx, auth, calls, env.sponsor, SponsorOptions{}); err == nil {
+			t.Fatalf("%s: accepted", name)
+		}
+		if _, err := env.svc.Store.Get(AuthorizationID(auth)); !errors.Is(err, ErrAuthorizationNotFound) {
+			t.Errorf("%s: rejected authorization was stored (err %v)", name, err)
+		}
+	}
+}
+
+// mustSign assina uma autorização pré-assinada pelo cliente (fora do store)
+func mustSign(t *testing.T, env *testEnv, authority *KeySigner, chainID, nonce uint64) *Authorization {
+	t.Helper()
+	auth, err := env.svc.signAuthorizationAt(context.Background(), env.svc.DelegateAddress, authority, chainID, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.svc.Store.Delete(AuthorizationID(auth)); err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func TestResigningReissuesExpiredAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.ExpiresAt = time.Now().Add(-time.Second)
+	if err := env.svc.Store.Put(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	// A mesma tupla assinada de novo volta a valer
+	again, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if AuthorizationID(again) != rec.ID {
+		t.Fatal("re-signing produced a different tuple")
+	}
+	if _, _, err := env.svc.ExecuteSponsored(ctx, again, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{}); err != nil {
+		t.Fatalf("re-issued authorization rejected: %v", err)
+	}
+}
+
+func TestDroppedTxReleasesAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	ctx := context.Background()
+	tx, auth := env.sponsorDelegation(t, newTestSigner(t))
+
+	if !env.chain.Drop(tx.Hash()) {
+		t.Fatal("tx not in pool")
+	}
+	if err := env.svc.PollTransactions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.TxHash != nil {
+		t.Fatalf("dropped tx still holds the authorization: %s", rec.TxHash)
+	}
+	if _, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(auth.Signer)}, env.sponsor, SponsorOptions{}); err != nil {
+		t.Fatalf("released authorization rejected: %v", err)
+	}
+}
+
+func TestCancelReleasesAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	ctx := context.Background()
+	tx, auth := env.sponsorDelegation(t, newTestSigner(t))
+
+	if _, err := env.svc.Cancel(ctx, tx.Hash(), env.sponsor, SponsorOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.TxHash != nil {
+		t.Fatalf("cancelled tx still holds the authorization: %s", rec.TxHash)
+	}
+}
+
+func TestSpeedUpCancelKeepsTxType(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	ctx := context.Background()
+	tx, auth := env.sponsorDelegation(t, newTestSigner(t))
+
+	cancel, err := env.svc.Cancel(ctx, tx.Hash(), env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	faster, err := env.svc.SpeedUp(ctx, cancel.TxHash, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatalf("speedup of the cancellation: %v", err)
+	}
+	sent, _, err := env.chain.TransactionByHash(ctx, faster.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Type() != types.DynamicFeeTxType || len(sent.SetCodeAuthorizations()) != 0 {
+		t.Fatalf("speedup of a cancellation is type %d with %d authorizations", sent.Type(), len(sent.SetCodeAuthorizations()))
+	}
+
+	env.chain.Mine()
+	if code, _ := env.chain.CodeAt(ctx, auth.Signer); len(code) != 0 {
+		t.Fatalf("cancelled delegation was applied: %x", code)
+	}
+}
+
+func TestOriginalMinedAfterSpeedUp(t *testing.T) {
+	env := newTestEnv(t)
+	env.chain.AutoMine = false
+	ctx := context.Background()
+	tx, auth := env.sponsorDelegation(t, newTestSigner(t))
+
+	faster, err := env.svc.SpeedUp(ctx, tx.Hash(), env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O node ainda tinha a original e ela foi minerada no lugar do speedup
+	env.chain.Drop(faster.TxHash)
+	if err := env.chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	env.chain.Mine()
+	if err := env.svc.PollTransactions(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	view, err := env.svc.TransactionStatus(ctx, faster.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Status != TxReplaced || view.ReplacedBy == nil || *view.ReplacedBy != tx.Hash() {
+		t.Fatalf("speedup view = %+v", view)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil || rec.TxHash == nil || *rec.TxHash != tx.Hash() {
+		t.Fatalf("record after poll = %+v, err %v", rec, err)
+	}
+	if err := env.svc.SyncAuthorizations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ := env.svc.AuthorizationRecordByID(AuthorizationID(auth)); rec.State != AuthorizationConsumed {
+		t.Fatalf("state = %s, want consumed", rec.State)
+	}
+}
+
+// failingStore recusa escritas depois de armado
+type failingStore struct {
+	AuthorizationStore
+	fail bool
+}
+
+func (f *failingStore) Update(id common.Hash, fn UpdateFunc) (*AuthorizationRecord, error) {
+	if f.fail {
+		return nil, errors.New("disk full")
+	}
+	return f.AuthorizationStore.Update(id, fn)
+}
+
+func TestConcurrentExecuteReservesAuthorization(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const requests = 8
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			_, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{})
+			errs <- err
+		}()
+	}
+	var ok int
+	for i := 0; i < requests; i++ {
+		err := <-errs
+		switch {
+		case err == nil:
+			ok++
+		case !strings.Contains(err.Error(), "being submitted"):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if ok != 1 {
+		t.Fatalf("%d requests got the authorization, want 1", ok)
+	}
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil || rec.State != AuthorizationSubmitting {
+		t.Fatalf("record = %+v, err %v", rec, err)
+	}
+}
+
+func TestFailedExecuteReleasesReservation(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Falha depois da validação: o teto de fee não cobre o base fee
+	if _, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{MaxFeePerGas: big.NewInt(1)}); !errors.Is(err, ErrMaxFeeTooLow) {
+		t.Fatalf("err = %v, want ErrMaxFeeTooLow", err)
+	}
+	tx, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatalf("reservation was not released: %v", err)
+	}
+
+	// Descartar a tx assinada também libera
+	env.svc.DiscardTransaction(tx)
+	rec, err := env.svc.AuthorizationRecordByID(AuthorizationID(auth))
+	if err != nil || rec.State != AuthorizationIssued || rec.TxHash != nil {
+		t.Fatalf("record after discard = %+v, err %v", rec, err)
+	}
+}
+
+func TestSendTransactionPropagatesStoreErrors(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	store := &failingStore{AuthorizationStore: env.svc.Store}
+	env.svc.Store = store
+	authority := newTestSigner(t)
+	auth, err := env.svc.SignDelegation(ctx, env.svc.DelegateAddress, authority)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, _, err := env.svc.ExecuteSponsored(ctx, auth, []Call{env.mintCall(authority.Address())}, env.sponsor, SponsorOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sem registrar o envio, a tx não vai para o node
+	store.fail = true
+	if err := env.svc.SendTransaction(ctx, tx); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("err = %v, want the store error", err)
+	}
+	if nonce, _ := env.chain.PendingNonceAt(ctx, env.sponsor.Address()); nonce != 0 {
+		t.Fatalf("tx was broadcast: sponsor pending nonce %d", nonce)
+	}
+	if st := env.svc.Nonces.Status(env.sponsor.Address()); len(st.Reserved) != 0 {
+		t.Fatalf("nonce still reserved: %+v", st)
+	}
+}
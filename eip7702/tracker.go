@@ -0,0 +1,401 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultConfirmations é quantos blocos (incluindo o da tx) contam como confirmada
+const DefaultConfirmations uint64 = 3
+
+// trackerRetention é por quanto tempo txs finalizadas continuam consultáveis
+const trackerRetention = 24 * time.Hour
+
+// TxStatus é o estado de uma tx acompanhada pelo tracker
+type TxStatus string
+
+const (
+	TxPending   TxStatus = "pending"   // no mempool, sem receipt
+	TxMined     TxStatus = "mined"     // receipt com sucesso, ainda sem confirmações suficientes
+	TxConfirmed TxStatus = "confirmed" // receipt com sucesso e confirmações suficientes
+	TxReverted  TxStatus = "reverted"  // minerada, execução revertida (o gas foi pago)
+	TxDropped   TxStatus = "dropped"   // saiu do mempool ou o nonce foi usado por outra tx
+	TxReplaced  TxStatus = "replaced"  // substituída por speedup/cancel e a substituta usou o nonce
+)
+
+// ErrTxNotFound indica que a tx não é acompanhada nem conhecida pelo node
+var ErrTxNotFound = errors.New("transaction not found")
+
+// TrackedTx é a visão de uma tx enviada pelo serviço
+type TrackedTx struct {
+	Hash              common.Hash    `json:"hash"`
+	From              common.Address `json:"from"`
+	Nonce             uint64         `json:"nonce"`
+	Status            TxStatus       `json:"status"`
+	BlockNumber       *uint64        `json:"block_number,omitempty"`
+	BlockHash         *common.Hash   `json:"block_hash,omitempty"`
+	Confirmations     uint64         `json:"confirmations"`
+	Required          uint64         `json:"required_confirmations"`
+	GasUsed           uint64         `json:"gas_used,omitempty"`
+	EffectiveGasPrice *big.Int       `json:"effective_gas_price,omitempty"`
+	Logs              []DecodedLog   `json:"logs,omitempty"`
+	ReplacedBy        *common.Hash   `json:"replaced_by,omitempty"` // última substituta (speedup/cancel)
+	Error             string         `json:"error,omitempty"`
+	SubmittedAt       time.Time      `json:"submitted_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+
+	tx *types.Transaction // tx original (com AuthList) para speedup/cancel
+}
+
+// Final indica que o tracker não precisa mais consultar a tx
+func (t *TrackedTx) Final() bool {
+	return t.Status == TxConfirmed || t.Status == TxDropped || t.Status == TxReplaced ||
+		(t.Status == TxReverted && t.Confirmations >= t.Required)
+}
+
+// DecodedLog é um log do receipt; eventos do SimpleDelegateContract vêm decodificados
+type DecodedLog struct {
+	Address common.Address         `json:"address"`
+	Event   string                 `json:"event,omitempty"`
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Topics  []common.Hash          `json:"topics,omitempty"` // só para logs desconhecidos
+	Data    hexutil.Bytes          `json:"data,omitempty"`   // só para logs desconhecidos
+}
+
+// TxTracker acompanha as txs enviadas até confirmarem, reverterem ou sumirem do mempool
+type TxTracker struct {
+	rpc           EthClient
+	confirmations uint64
+	mu            sync.RWMutex
+	txs           map[common.Hash]*TrackedTx
+}
+
+func NewTxTracker(rpc EthClient, confirmations uint64) *TxTracker {
+	if confirmations == 0 {
+		confirmations = DefaultConfirmations
+	}
+	return &TxTracker{rpc: rpc, confirmations: confirmations, txs: make(map[common.Hash]*TrackedTx)}
+}
+
+// Track passa a acompanhar uma tx recém-enviada
+func (t *TxTracker) Track(tx *types.Transaction, from common.Address) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.txs[tx.Hash()] = &TrackedTx{
+		Hash:        tx.Hash(),
+		From:        from,
+		Nonce:       tx.Nonce(),
+		Status:      TxPending,
+		Required:    t.confirmations,
+		SubmittedAt: now,
+		UpdatedAt:   now,
+		tx:          tx,
+	}
+}
+
+// Replace registra a substituta de uma tx (mesmo nonce) e passa a acompanhá-la
+func (t *TxTracker) Replace(old common.Hash, replacement *types.Transaction, from common.Address) {
+	t.Track(replacement, from)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tracked, ok := t.txs[old]; ok {
+		hash := replacement.Hash()
+		tracked.ReplacedBy = &hash
+		tracked.UpdatedAt = time.Now()
+	}
+}
+
+// transaction devolve a tx assinada retida para uma tx acompanhada
+func (t *TxTracker) transaction(hash common.Hash) (*TrackedTx, *types.Transaction, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tracked, ok := t.txs[hash]
+	if !ok || tracked.tx == nil {
+		return nil, nil, ErrTxNotFound
+	}
+	cp := *tracked
+	return &cp, tracked.tx, nil
+}
+
+// Get devolve o estado da tx. Txs que o serviço não enviou são consultadas no node.
+func (t *TxTracker) Get(ctx context.Context, hash common.Hash) (*TrackedTx, error) {
+	t.mu.RLock()
+	tracked, ok := t.txs[hash]
+	var cp TrackedTx
+	if ok {
+		cp = *tracked
+	}
+	t.mu.RUnlock()
+	if ok {
+		return &cp, nil
+	}
+
+	// Não enviada pelo serviço: montar a visão a partir do node
+	tx, pending, err := t.rpc.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, ErrTxNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	view := &TrackedTx{Hash: hash, Nonce: tx.Nonce(), Status: TxPending, Required: t.confirmations, UpdatedAt: time.Now()}
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		view.From = from
+	}
+	if !pending {
+		head, err := t.rpc.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		if err := t.lookupReceipt(ctx, view, head); err != nil {
+			return nil, err
+		}
+	}
+	return view, nil
+}
+
+// Poll atualiza todas as txs não finalizadas e devolve as que terminaram sem ser
+// mineradas (dropped ou replaced)
+func (t *TxTracker) Poll(ctx context.Context) ([]*TrackedTx, error) {
+	head, err := t.rpc.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	t.mu.RLock()
+	open := make([]TrackedTx, 0, len(t.txs))
+	for _, tracked := range t.txs {
+		if !tracked.Final() {
+			open = append(open, *tracked)
+		}
+	}
+	t.mu.RUnlock()
+
+	// Consultas ao node fora do lock; grava o resultado no final
+	var unmined []*TrackedTx
+	for i := range open {
+		view := &open[i]
+		if err := t.refresh(ctx, view, head); err != nil {
+			view.Error = err.Error()
+		}
+
+		t.mu.Lock()
+		if current, ok := t.txs[view.Hash]; ok {
+			if view.Status != TxReplaced {
+				view.ReplacedBy = current.ReplacedBy // pode ter sido substituída durante o poll
+			}
+			cp := *view
+			t.txs[view.Hash] = &cp
+		}
+		t.mu.Unlock()
+
+		if view.Status == TxDropped || view.Status == TxReplaced {
+			cp := *view
+			unmined = append(unmined, &cp)
+		}
+	}
+
+	t.prune()
+	return unmined, nil
+}
+
+// refresh consulta receipt/mempool de uma tx aberta
+func (t *TxTracker) refresh(ctx context.Context, view *TrackedTx, head uint64) error {
+	view.UpdatedAt = time.Now()
+	view.Error = ""
+
+	receipt, err := t.rpc.TransactionReceipt(ctx, view.Hash)
+	if err == nil && receipt != nil {
+		view.applyReceipt(receipt, head)
+		return nil
+	}
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	// Sem receipt (inclusive após reorg): volta a pending até provar o contrário
+	view.Status = TxPending
+	view.BlockNumber, view.BlockHash = nil, nil
+	view.Confirmations = 0
+
+	// Nonce já usado por outra tx: esta nunca será minerada
+	nonce, err := t.rpc.NonceAt(ctx, view.From)
+	if err != nil {
+		return fmt.Errorf("failed to get sender nonce: %w", err)
+	}
+	if nonce > view.Nonce {
+		// A tx pode ter sido minerada entre as duas consultas
+		if receipt, err := t.rpc.TransactionReceipt(ctx, view.Hash); err == nil && receipt != nil {
+			view.applyReceipt(receipt, head)
+			return nil
+		}
+		// Outra tx da mesma cadeia de substituição (original, speedup ou cancel) foi minerada
+		mined, err := t.minedReplacement(ctx, view)
+		if err != nil {
+			return err
+		}
+		if mined != nil {
+			view.Status = TxReplaced
+			view.ReplacedBy = mined
+			return nil
+		}
+		if view.ReplacedBy != nil {
+			view.Status = TxReplaced
+			return nil
+		}
+		view.Status = TxDropped
+		view.Error = "nonce used by another transaction"
+		return nil
+	}
+
+	// O node esqueceu a tx (evicted do mempool)
+	if _, _, err := t.rpc.TransactionByHash(ctx, view.Hash); errors.Is(err, ethereum.NotFound) {
+		view.Status = TxDropped
+		view.Error = "transaction no longer in mempool"
+	}
+	return nil
+}
+
+// minedReplacement procura, entre as txs acompanhadas no mesmo nonce do mesmo sponsor,
+// uma que tenha sido minerada
+func (t *TxTracker) minedReplacement(ctx context.Context, view *TrackedTx) (*common.Hash, error) {
+	t.mu.RLock()
+	var chain []common.Hash
+	for hash, tracked := range t.txs {
+		if hash != view.Hash && tracked.From == view.From && tracked.Nonce == view.Nonce {
+			chain = append(chain, hash)
+		}
+	}
+	t.mu.RUnlock()
+
+	for _, hash := range chain {
+		receipt, err := t.rpc.TransactionReceipt(ctx, hash)
+		if err == nil && receipt != nil {
+			return &hash, nil
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to get receipt: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// lookupReceipt busca o receipt de uma tx externa já minerada
+func (t *TxTracker) lookupReceipt(ctx context.Context, view *TrackedTx, head uint64) error {
+	receipt, err := t.rpc.TransactionReceipt(ctx, view.Hash)
+	if errors.Is(err, ethereum.NotFound) {
+		// Fora do mempool mas o receipt ainda não foi indexado: segue pending
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get receipt: %w", err)
+	}
+	view.applyReceipt(receipt, head)
+	return nil
+}
+
+// applyReceipt preenche bloco, gas, logs e confirmações a partir do receipt
+func (view *TrackedTx) applyReceipt(receipt *types.Receipt, head uint64) {
+	block := receipt.BlockNumber.Uint64()
+	view.BlockNumber = &block
+	view.BlockHash = &receipt.BlockHash
+	view.GasUsed = receipt.GasUsed
+	view.EffectiveGasPrice = receipt.EffectiveGasPrice
+	view.Logs = decodeLogs(receipt.Logs)
+	if head >= block {
+		view.Confirmations = head - block + 1
+	}
+
+	switch {
+	case receipt.Status != types.ReceiptStatusSuccessful:
+		view.Status = TxReverted
+	case view.Confirmations >= view.Required:
+		view.Status = TxConfirmed
+	default:
+		view.Status = TxMined
+	}
+}
+
+// prune esquece txs finalizadas há mais de trackerRetention
+func (t *TxTracker) prune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for hash, tracked := range t.txs {
+		if tracked.Final() && time.Since(tracked.UpdatedAt) > trackerRetention {
+			delete(t.txs, hash)
+		}
+	}
+}
+
+// decodeLogs decodifica Executed/TokenOperation; outros logs vão crus
+func decodeLogs(logs []*types.Log) []DecodedLog {
+	out := make([]DecodedLog, 0, len(logs))
+	for _, l := range logs {
+		decoded := DecodedLog{Address: l.Address}
+		if len(l.Topics) > 0 {
+			if event, err := simpleDelegateABI.EventByID(l.Topics[0]); err == nil {
+				if args, err := decodeEvent(event, l); err == nil {
+					decoded.Event = event.Name
+					decoded.Args = jsonArgs(args)
+					out = append(out, decoded)
+					continue
+				}
+			}
+		}
+		decoded.Topics = l.Topics
+		decoded.Data = l.Data
+		out = append(out, decoded)
+	}
+	return out
+}
+
+// decodeEvent lê os inputs não indexados do data e os indexados dos topics, na ordem
+// em que aparecem no evento (Topics[0] é a assinatura)
+func decodeEvent(event *abi.Event, l *types.Log) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(args, l.Data); err != nil {
+		return nil, err
+	}
+
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(l.Topics)-1 != len(indexed) {
+		return nil, fmt.Errorf("event %s has %d indexed inputs, log has %d topics", event.Name, len(indexed), len(l.Topics)-1)
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// jsonArgs converte valores do ABI para formatos JSON seguros (uint256 como string, bytes em hex)
+func jsonArgs(args map[string]interface{}) map[string]interface{} {
+	for k, v := range args {
+		switch val := v.(type) {
+		case *big.Int:
+			args[k] = val.String()
+		case []byte:
+			args[k] = hexutil.Bytes(val)
+		}
+	}
+	return args
+}
@@ -0,0 +1,216 @@
+package eip7702
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDecodeEventIndexesTopicsByPosition(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(`[{"name": "Moved", "type": "event", "inputs": [
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "value", "type": "uint256"},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "id", "type": "uint256", "indexed": true}
+	]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := parsed.Events["Moved"]
+	from, to := common.Address{1}, common.Address{2}
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args, err := decodeEvent(&event, &types.Log{
+		Topics: []common.Hash{event.ID, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(9))},
+		Data:   data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["from"] != from || args["to"] != to {
+		t.Fatalf("from = %v, to = %v", args["from"], args["to"])
+	}
+	if id, ok := args["id"].(*big.Int); !ok || id.Int64() != 9 {
+		t.Fatalf("id = %v", args["id"])
+	}
+	if value, ok := args["value"].(*big.Int); !ok || value.Int64() != 5 {
+		t.Fatalf("value = %v", args["value"])
+	}
+
+	// Topics faltando: não dá para saber qual input é qual
+	if _, err := decodeEvent(&event, &types.Log{Topics: []common.Hash{event.ID, common.BytesToHash(from.Bytes())}, Data: data}); err == nil {
+		t.Fatal("log with missing topics decoded")
+	}
+}
+
+func TestDecodeLogsExecuted(t *testing.T) {
+	event := simpleDelegateABI.Events["Executed"]
+	to := common.Address{7}
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(3), []byte{0xab})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logs := decodeLogs([]*types.Log{
+		{Address: common.Address{1}, Topics: []common.Hash{event.ID, common.BytesToHash(to.Bytes())}, Data: data},
+		{Address: common.Address{1}, Topics: []common.Hash{{0xff}}},
+	})
+	if logs[0].Event != "Executed" || logs[0].Args["to"] != to || logs[0].Args["value"] != "3" {
+		t.Fatalf("executed log = %+v", logs[0])
+	}
+	if logs[1].Event != "" || len(logs[1].Topics) != 1 {
+		t.Fatalf("unknown log = %+v", logs[1])
+	}
+}
+
+// trackerRPC responde só o que o tracker consulta
+type trackerRPC struct {
+	EthClient
+	head     uint64
+	nonces   map[common.Address]uint64
+	txs      map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (r *trackerRPC) BlockNumber(ctx context.Context) (uint64, error) { return r.head, nil }
+
+func (r *trackerRPC) NonceAt(ctx context.Context, from common.Address) (uint64, error) {
+	return r.nonces[from], nil
+}
+
+func (r *trackerRPC) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, ok := r.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	_, mined := r.receipts[hash]
+	return tx, !mined, nil
+}
+
+func (r *trackerRPC) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, ok := r.receipts[hash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// mine inclui a tx no bloco com um log Executed
+func (r *trackerRPC) mine(t *testing.T, tx *types.Transaction, from common.Address, block uint64) {
+	t.Helper()
+	event := simpleDelegateABI.Events["Executed"]
+	data, err := event.Inputs.NonIndexed().Pack(big.NewInt(1), []byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.receipts[tx.Hash()] = &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            tx.Hash(),
+		BlockNumber:       new(big.Int).SetUint64(block),
+		BlockHash:         common.Hash{byte(block)},
+		GasUsed:           50_000,
+		EffectiveGasPrice: big.NewInt(3),
+		Logs:              []*types.Log{{Address: from, Topics: []common.Hash{event.ID, {}}, Data: data}},
+	}
+	r.nonces[from] = tx.Nonce() + 1
+}
+
+func newTrackerRPC(t *testing.T) (*trackerRPC, *types.Transaction, common.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1337)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       100_000,
+		To:        &common.Address{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpc := &trackerRPC{
+		head:     12,
+		nonces:   make(map[common.Address]uint64),
+		txs:      map[common.Hash]*types.Transaction{tx.Hash(): tx},
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+	return rpc, tx, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// requireMined confere a visão de uma tx minerada no bloco 10 com head 12
+func requireMined(t *testing.T, view *TrackedTx) {
+	t.Helper()
+	if view.Status != TxConfirmed || view.BlockNumber == nil || *view.BlockNumber != 10 || view.Confirmations != 3 {
+		t.Fatalf("view = %+v", view)
+	}
+	if view.GasUsed != 50_000 || view.EffectiveGasPrice.Int64() != 3 {
+		t.Fatalf("gas used %d at %s", view.GasUsed, view.EffectiveGasPrice)
+	}
+	if len(view.Logs) != 1 || view.Logs[0].Event != "Executed" {
+		t.Fatalf("logs = %+v", view.Logs)
+	}
+}
+
+func TestTrackerGetUntrackedTx(t *testing.T) {
+	rpc, tx, from := newTrackerRPC(t)
+	tracker := NewTxTracker(rpc, 3)
+	ctx := context.Background()
+
+	view, err := tracker.Get(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view.Status != TxPending || view.BlockNumber != nil || view.From != from {
+		t.Fatalf("pending view = %+v", view)
+	}
+
+	rpc.mine(t, tx, from, 10)
+	view, err = tracker.Get(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireMined(t, view)
+
+	if _, err := tracker.Get(ctx, common.Hash{1}); !errors.Is(err, ErrTxNotFound) {
+		t.Fatalf("unknown tx err = %v", err)
+	}
+}
+
+func TestTrackerPollTrackedTx(t *testing.T) {
+	rpc, tx, from := newTrackerRPC(t)
+	tracker := NewTxTracker(rpc, 3)
+	ctx := context.Background()
+	tracker.Track(tx, from)
+
+	if dropped, err := tracker.Poll(ctx); err != nil || len(dropped) != 0 {
+		t.Fatalf("dropped = %v, err %v", dropped, err)
+	}
+	if view, _ := tracker.Get(ctx, tx.Hash()); view.Status != TxPending {
+		t.Fatalf("pending view = %+v", view)
+	}
+
+	rpc.mine(t, tx, from, 10)
+	if _, err := tracker.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	view, err := tracker.Get(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireMined(t, view)
+}
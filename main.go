@@ -2,9 +2,15 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/omnes/eip7702/eip7702"
@@ -18,12 +24,22 @@ func main() {
 		log.Fatal("RPC_URL não definido")
 	}
 
-	rpc, err := eip7702.NewEthRPCClient(rpcURL)
+	timeouts, err := loadRPCTimeouts()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	chainID, err := rpc.ChainID()
+	node, err := dialRPC(rpcURL, timeouts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	retry, err := loadRetryPolicy()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rpc := eip7702.NewRetryingClient(node, retry)
+
+	chainID, err := rpc.ChainID(context.Background())
 	if err != nil {
 		log.Fatalf("Failed to get chain ID: %v", err)
 	}
@@ -32,13 +48,300 @@ func main() {
 		log.Fatal("Chain ID is nil")
 	}
 
-	svc := &eip7702.DelegationService{ChainID: chainID, RPC: rpc}
+	svc := eip7702.NewDelegationService(chainID, rpc)
+	svc.AllowUniversal = os.Getenv("ALLOW_UNIVERSAL_AUTH") == "true"
 	if svc.RPC == nil {
 		log.Fatal("RPC client is nil in service")
 	}
 
-	h := eip7702.NewDelegationHandlers(svc)
+	if ttl := os.Getenv("AUTH_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid AUTH_TTL %q", ttl)
+		}
+		svc.AuthorizationTTL = d
+	}
+	if retention := os.Getenv("AUTH_RETENTION"); retention != "" {
+		d, err := time.ParseDuration(retention)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid AUTH_RETENTION %q", retention)
+		}
+		svc.AuthorizationRetention = d
+	}
+	if margin := os.Getenv("GAS_MARGIN_PERCENT"); margin != "" {
+		pct, err := strconv.ParseUint(margin, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid GAS_MARGIN_PERCENT %q", margin)
+		}
+		svc.GasMarginPercent = pct
+	}
+	if mult := os.Getenv("BASE_FEE_MULTIPLIER"); mult != "" {
+		m, err := strconv.ParseFloat(mult, 64)
+		if err != nil || m < 1 {
+			log.Fatalf("Invalid BASE_FEE_MULTIPLIER %q (must be >= 1)", mult)
+		}
+		svc.BaseFeeMultiplier = m
+	}
+	if path := os.Getenv("AUTH_STORE_PATH"); path != "" {
+		store, err := eip7702.NewFileAuthorizationStore(path)
+		if err != nil {
+			log.Fatalf("Failed to open authorization store: %v", err)
+		}
+		svc.Store = store
+	}
+	if conf := os.Getenv("TX_CONFIRMATIONS"); conf != "" {
+		n, err := strconv.ParseUint(conf, 10, 64)
+		if err != nil || n == 0 {
+			log.Fatalf("Invalid TX_CONFIRMATIONS %q", conf)
+		}
+		svc.Tracker = eip7702.NewTxTracker(rpc, n)
+	}
+	go syncAuthorizations(svc, time.Minute)
+	go trackTransactions(svc, 4*time.Second)
+
+	sponsors, err := loadSponsors()
+	if err != nil {
+		log.Fatalf("Failed to load sponsors: %v", err)
+	}
+	for _, sp := range sponsors.List() {
+		log.Printf("Sponsor %q loaded: %s (default=%v)", sp.Name, sp.Address.Hex(), sp.Default)
+	}
+
+	h := eip7702.NewDelegationHandlers(svc, sponsors)
+
+	if svc.AllowUniversal {
+		log.Printf("WARNING: chain-agnostic (chain_id = 0) authorizations are accepted")
+	}
 
 	log.Printf("EIP-7702 API online – chainID %v", chainID)
 	log.Fatal(http.ListenAndServe(":8080", h.Routes()))
 }
+
+// syncAuthorizations fecha periodicamente as autorizações que a authority já usou
+func syncAuthorizations(svc *eip7702.DelegationService, every time.Duration) {
+	for range time.Tick(every) {
+		ctx, cancel := context.WithTimeout(context.Background(), every)
+		if err := svc.SyncAuthorizations(ctx); err != nil {
+			log.Printf("Authorization sync failed: %v", err)
+		}
+		cancel()
+	}
+}
+
+// trackTransactions atualiza periodicamente o status das txs enviadas
+func trackTransactions(svc *eip7702.DelegationService, every time.Duration) {
+	for range time.Tick(every) {
+		ctx, cancel := context.WithTimeout(context.Background(), every)
+		if err := svc.PollTransactions(ctx); err != nil {
+			log.Printf("Transaction tracking failed: %v", err)
+		}
+		cancel()
+	}
+}
+
+// loadRetryPolicy lê quantas vezes repetir falhas transitórias do RPC.
+//
+//	RPC_RETRY_ATTEMPTS=3      (1 = sem repetição)
+//	RPC_RETRY_BACKOFF=250ms   (dobra a cada tentativa, até RPC_RETRY_MAX_BACKOFF)
+//	RPC_RETRY_MAX_BACKOFF=2s
+func loadRetryPolicy() (eip7702.RetryPolicy, error) {
+	policy := eip7702.DefaultRetryPolicy()
+
+	if v := os.Getenv("RPC_RETRY_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return policy, fmt.Errorf("invalid RPC_RETRY_ATTEMPTS %q", v)
+		}
+		policy.Attempts = n
+	}
+	for env, dst := range map[string]*time.Duration{
+		"RPC_RETRY_BACKOFF":     &policy.Backoff,
+		"RPC_RETRY_MAX_BACKOFF": &policy.MaxBackoff,
+	} {
+		if v := os.Getenv(env); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return policy, fmt.Errorf("invalid %s %q", env, v)
+			}
+			*dst = d
+		}
+	}
+	return policy, nil
+}
+
+// dialRPC conecta ao node; com várias URLs (RPC_URL=https://a,https://b) usa o
+// MultiRPCClient, com failover, broadcast das txs e quorum opcional nas leituras.
+//
+//	RPC_READ_QUORUM=2         (padrão 1 = sem quorum)
+//	RPC_HEALTH_INTERVAL=15s
+//	RPC_MAX_BLOCK_LAG=5
+func dialRPC(spec string, timeouts eip7702.RPCTimeouts) (eip7702.EthClient, error) {
+	urls := strings.Split(spec, ",")
+	if len(urls) == 1 {
+		return eip7702.NewEthRPCClient(strings.TrimSpace(spec), timeouts)
+	}
+
+	var endpoints []eip7702.RPCEndpoint
+	for i, raw := range urls {
+		raw = strings.TrimSpace(raw)
+		client, err := eip7702.NewEthRPCClient(raw, timeouts)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, eip7702.RPCEndpoint{Name: endpointName(raw, i), Client: client})
+	}
+
+	quorum := 1
+	if v := os.Getenv("RPC_READ_QUORUM"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid RPC_READ_QUORUM %q", v)
+		}
+		quorum = n
+	}
+	multi, err := eip7702.NewMultiRPCClient(endpoints, quorum)
+	if err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("RPC_MAX_BLOCK_LAG"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RPC_MAX_BLOCK_LAG %q", v)
+		}
+		multi.MaxBlockLag = n
+	}
+
+	every := 15 * time.Second
+	if v := os.Getenv("RPC_HEALTH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid RPC_HEALTH_INTERVAL %q", v)
+		}
+		every = d
+	}
+	checkRPCHealth(multi, every)
+	go func() {
+		for range time.Tick(every) {
+			checkRPCHealth(multi, every)
+		}
+	}()
+
+	log.Printf("RPC: %d endpoints, read quorum %d", len(endpoints), quorum)
+	return multi, nil
+}
+
+// checkRPCHealth atualiza a saúde dos endpoints e avisa os que saíram da rotação
+func checkRPCHealth(multi *eip7702.MultiRPCClient, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, h := range multi.CheckHealth(ctx) {
+		if !h.Healthy {
+			log.Printf("RPC endpoint %s unhealthy: %s", h.Name, h.Error)
+		}
+	}
+}
+
+// endpointName usa só o host: a URL costuma carregar a API key do provedor
+func endpointName(raw string, i int) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return fmt.Sprintf("%s#%d", u.Host, i+1)
+	}
+	return fmt.Sprintf("rpc#%d", i+1)
+}
+
+// loadRPCTimeouts lê os timeouts das chamadas ao node.
+//
+//	RPC_TIMEOUT=10s
+//	RPC_TIMEOUTS=eth_estimateGas=20s,eth_sendRawTransaction=30s
+//
+// RPC_TIMEOUTS sobrescreve só os métodos listados; "0" remove o limite do método.
+func loadRPCTimeouts() (eip7702.RPCTimeouts, error) {
+	timeouts := eip7702.DefaultRPCTimeouts()
+
+	if v := os.Getenv("RPC_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return timeouts, fmt.Errorf("invalid RPC_TIMEOUT %q", v)
+		}
+		timeouts.Default = d
+	}
+
+	spec := os.Getenv("RPC_TIMEOUTS")
+	if spec == "" {
+		return timeouts, nil
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		method, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || method == "" {
+			return timeouts, fmt.Errorf("invalid RPC_TIMEOUTS entry %q (expected method=duration)", entry)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return timeouts, fmt.Errorf("invalid RPC_TIMEOUTS duration for %s: %q", method, value)
+		}
+		timeouts.Methods[method] = d
+	}
+	return timeouts, nil
+}
+
+// loadSponsors carrega os sponsors de arquivos keystore JSON.
+//
+//	SPONSOR_KEYSTORES=main=/keys/main.json,ops=/keys/ops.json
+//	SPONSOR_DEFAULT=main
+//
+// A passphrase de cada sponsor vem de SPONSOR_<NOME>_PASSWORD ou
+// SPONSOR_<NOME>_PASSWORD_FILE, com SPONSOR_PASSWORD(_FILE) como fallback.
+func loadSponsors() (*eip7702.SponsorRegistry, error) {
+	registry := eip7702.NewSponsorRegistry()
+
+	spec := os.Getenv("SPONSOR_KEYSTORES")
+	if spec == "" {
+		log.Printf("WARNING: SPONSOR_KEYSTORES não definido – rotas patrocinadas indisponíveis")
+		return registry, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		name, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid SPONSOR_KEYSTORES entry %q (expected name=path)", entry)
+		}
+
+		passphrase, err := sponsorPassphrase(name)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := eip7702.NewKeystoreSigner(path, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.Add(name, signer); err != nil {
+			return nil, err
+		}
+	}
+
+	if def := os.Getenv("SPONSOR_DEFAULT"); def != "" {
+		if err := registry.SetDefault(def); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// sponsorPassphrase lê a passphrase do sponsor do env ou de arquivo
+func sponsorPassphrase(name string) (string, error) {
+	prefix := "SPONSOR_" + strings.ToUpper(name) + "_"
+	for _, p := range []string{prefix, "SPONSOR_"} {
+		if v, ok := os.LookupEnv(p + "PASSWORD"); ok {
+			return v, nil
+		}
+		if file := os.Getenv(p + "PASSWORD_FILE"); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return "", fmt.Errorf("failed to read passphrase file for sponsor %q: %w", name, err)
+			}
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}
+	}
+	return "", fmt.Errorf("no passphrase configured for sponsor %q", name)
+}